@@ -5,6 +5,7 @@ go 1.15
 require (
 	github.com/edwarnicke/exechelper v1.0.2
 	github.com/edwarnicke/serialize v1.0.0
+	github.com/ghodss/yaml v1.0.0
 	github.com/golang/protobuf v1.4.2
 	github.com/networkservicemesh/api v0.0.0-20201014184533-ca42a07d7e15
 	github.com/networkservicemesh/sdk v0.0.0-20201019071402-39aa586f0a55
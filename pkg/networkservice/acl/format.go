@@ -0,0 +1,102 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"fmt"
+	"strings"
+
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+)
+
+// FormatRule formats rule back into the key=value grammar understood by LoadRules and MapToRules
+// Keys are always written in the same order and values that match the defaults are omitted, so that parsing the
+// result yields a rule equal to the original one.
+func FormatRule(rule *vppacl.ACL_Rule) string {
+	kvs := []string{kv(action, strings.ToLower(rule.GetAction().String()))}
+	if macIPRule := rule.GetMacipRule(); macIPRule != nil {
+		kvs = append(kvs,
+			kv(srcNet, fmt.Sprintf("%s/%d", macIPRule.GetSourceAddress(), macIPRule.GetSourceAddressPrefix())),
+			kv(srcMac, macIPRule.GetSourceMacAddress()),
+		)
+		if macIPRule.GetSourceMacAddressMask() != defaultMacMask {
+			kvs = append(kvs, kv(srcMacMask, macIPRule.GetSourceMacAddressMask()))
+		}
+		return strings.Join(kvs, ",")
+	}
+
+	ipRule := rule.GetIpRule()
+	if network := ipRule.GetIp().GetSourceNetwork(); network != "" {
+		kvs = append(kvs, kv(srcNet, network))
+	}
+	if network := ipRule.GetIp().GetDestinationNetwork(); network != "" {
+		kvs = append(kvs, kv(dstNet, network))
+	}
+	switch {
+	case ipRule.GetIcmp() != nil:
+		icmp := ipRule.GetIcmp()
+		if icmp.GetIcmpv6() {
+			kvs = append(kvs, kv(proto, protoICMPv6))
+		} else {
+			kvs = append(kvs, kv(proto, protoICMP))
+		}
+		kvs = appendRange(kvs, icmpType, icmp.GetIcmpTypeRange())
+		kvs = appendRange(kvs, icmpCode, icmp.GetIcmpCodeRange())
+	case ipRule.GetTcp() != nil:
+		tcp := ipRule.GetTcp()
+		kvs = append(kvs, kv(proto, protoTCP))
+		kvs = appendPortRange(kvs, tcpSrcLowPort, tcpSrcUpPort, tcp.GetSourcePortRange())
+		kvs = appendPortRange(kvs, tcpLowPort, tcpUpPort, tcp.GetDestinationPortRange())
+		if tcp.GetTcpFlagsMask() != 0 {
+			kvs = append(kvs, kv(tcpFlagsMask, fmt.Sprint(tcp.GetTcpFlagsMask())))
+		}
+		if tcp.GetTcpFlagsValue() != 0 {
+			kvs = append(kvs, kv(tcpFlagsValue, fmt.Sprint(tcp.GetTcpFlagsValue())))
+		}
+	case ipRule.GetUdp() != nil:
+		udp := ipRule.GetUdp()
+		kvs = append(kvs, kv(proto, protoUDP))
+		kvs = appendPortRange(kvs, udpSrcLowPort, udpSrcUpPort, udp.GetSourcePortRange())
+		kvs = appendPortRange(kvs, udpLowPort, udpUpPort, udp.GetDestinationPortRange())
+	}
+	return strings.Join(kvs, ",")
+}
+
+func kv(key, value string) string {
+	return key + "=" + value
+}
+
+func appendRange(kvs []string, name string, r *vppacl.ACL_Rule_IpRule_Icmp_Range) []string {
+	switch {
+	case r.GetFirst() == anyFirst && r.GetLast() == anyICMPLast:
+		return kvs
+	case r.GetFirst() == r.GetLast():
+		return append(kvs, kv(name, fmt.Sprint(r.GetFirst())))
+	default:
+		return append(kvs, kv(name, fmt.Sprintf("%d-%d", r.GetFirst(), r.GetLast())))
+	}
+}
+
+func appendPortRange(kvs []string, lowName, upName string, r *vppacl.ACL_Rule_IpRule_PortRange) []string {
+	if r.GetLowerPort() != anyFirst {
+		kvs = append(kvs, kv(lowName, fmt.Sprint(r.GetLowerPort())))
+	}
+	if r.GetUpperPort() != anyLast {
+		kvs = append(kvs, kv(upName, fmt.Sprint(r.GetUpperPort())))
+	}
+	return kvs
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+)
+
+func TestFormatRule_RoundTrip(t *testing.T) {
+	for _, rule := range []string{
+		"action=permit",
+		"action=deny,srcnet=10.0.0.0/24,dstnet=10.0.1.0/24",
+		"action=permit,proto=tcp",
+		"action=permit,dstnet=fd00::/64,proto=tcp,tcpsrclowport=1024,tcplowport=80,tcpupport=88,tcpflagsmask=18,tcpflagsvalue=2",
+		"action=reflect,proto=udp,udpsrcupport=1023,udplowport=53,udpupport=53",
+		"action=permit,proto=icmp,icmptype=8,icmpcode=0",
+		"action=permit,dstnet=fd00::/64,proto=icmpv6,icmptype=128-129",
+		"action=permit,srcnet=10.0.0.1/32,srcmac=0a:1b:3c:4d:5e:6f",
+		"action=deny,srcnet=10.0.0.0/24,srcmac=0a:1b:3c:00:00:00,srcmacmask=ff:ff:ff:00:00:00",
+	} {
+		rules, err := acl.LoadRules([]*acl.NamedRule{{Name: "rule", Rule: rule}})
+		require.NoError(t, err, rule)
+		require.Len(t, rules, 1)
+		assert.Equal(t, rule, acl.FormatRule(rules[0]))
+
+		again, err := acl.LoadRules([]*acl.NamedRule{{Name: "rule", Rule: acl.FormatRule(rules[0])}})
+		require.NoError(t, err)
+		assert.Equal(t, rules, again)
+	}
+}
+
+func TestLoadRules_Grammar(t *testing.T) {
+	rules, err := acl.LoadRules([]*acl.NamedRule{
+		{Name: "0", Rule: "action=permit,proto=6,tcpsrclowport=1024,tcpsrcupport=2048,tcpflagsmask=2,tcpflagsvalue=2"},
+		{Name: "1", Rule: "action=permit,proto=58,icmpcode=1-3"},
+		{Name: "2", Rule: "action=permit,icmptype=8,icmpv6=true"},
+		{Name: "3", Rule: "action=permit,srcnet=10.0.0.5/24,srcmac=0A:1B:3C:4D:5E:6F"},
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 4)
+
+	tcp := rules[0].GetIpRule().GetTcp()
+	require.NotNil(t, tcp)
+	assert.Equal(t, &vppacl.ACL_Rule_IpRule_PortRange{LowerPort: 1024, UpperPort: 2048}, tcp.GetSourcePortRange())
+	assert.Equal(t, &vppacl.ACL_Rule_IpRule_PortRange{LowerPort: 0, UpperPort: 65535}, tcp.GetDestinationPortRange())
+	assert.Equal(t, uint32(2), tcp.GetTcpFlagsMask())
+	assert.Equal(t, uint32(2), tcp.GetTcpFlagsValue())
+
+	icmp := rules[1].GetIpRule().GetIcmp()
+	require.NotNil(t, icmp)
+	assert.True(t, icmp.GetIcmpv6())
+	assert.Equal(t, &vppacl.ACL_Rule_IpRule_Icmp_Range{First: 1, Last: 3}, icmp.GetIcmpCodeRange())
+	assert.Equal(t, &vppacl.ACL_Rule_IpRule_Icmp_Range{First: 0, Last: 255}, icmp.GetIcmpTypeRange())
+
+	assert.True(t, rules[2].GetIpRule().GetIcmp().GetIcmpv6())
+
+	assert.Nil(t, rules[3].GetIpRule())
+	assert.Equal(t, &vppacl.ACL_Rule_MacIpRule{
+		SourceAddress:        "10.0.0.5",
+		SourceAddressPrefix:  24,
+		SourceMacAddress:     "0a:1b:3c:4d:5e:6f",
+		SourceMacAddressMask: "ff:ff:ff:ff:ff:ff",
+	}, rules[3].GetMacipRule())
+}
+
+func TestLoadRules_GrammarErrors(t *testing.T) {
+	for _, rule := range []string{
+		"action=permit,proto=47",
+		"action=permit,proto=udp,tcplowport=80",
+		"action=permit,tcplowport=80,udplowport=53",
+		"action=permit,tcplowport=90,tcpupport=80",
+		"action=permit,tcpflagsmask=1,tcpflagsvalue=2",
+		"action=permit,proto=icmp,icmpv6=true",
+		"action=permit,icmptype=10-5",
+		"action=permit,srcmac=0a:1b:3c:4d:5e:6f",
+		"action=permit,srcnet=10.0.0.0/24,srcmac=0a:1b:3c:4d:5e:6f,dstnet=10.0.1.0/24",
+		"action=permit,srcmacmask=ff:ff:ff:00:00:00",
+		"action=permit,dstport=80",
+	} {
+		_, err := acl.LoadRules([]*acl.NamedRule{{Name: "rule", Rule: rule}})
+		assert.Error(t, err, rule)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+const (
+	defaultName = "nsm-acl"
+)
+
+// Direction - direction of the traffic, from the point of view of vpp, the acl is applied to
+type Direction int
+
+const (
+	// Ingress - apply the acl to traffic received by vpp from the interface
+	Ingress Direction = 1 << iota
+	// Egress - apply the acl to traffic sent by vpp to the interface
+	Egress
+	// Both - apply the acl in both directions
+	Both = Ingress | Egress
+)
+
+// Option - option for acl.NewServer
+type Option func(a *acl)
+
+// WithName - sets the name of the shared acl.  Defaults to "nsm-acl"
+func WithName(name string) Option {
+	return func(a *acl) {
+		a.name = name
+	}
+}
+
+// WithDirection - sets the direction the acl is applied in.  Defaults to Ingress
+func WithDirection(direction Direction) Option {
+	return func(a *acl) {
+		a.direction = direction
+	}
+}
+
+// Policy - acl rules for the connections to a network service, optionally narrowed down by labels
+type Policy struct {
+	// Name - name of the policy, the acl of the policy is named "<name of the acl>-<Name>"
+	Name string
+	// NetworkService - name of the network service selected by the policy.  Empty selects every network service
+	NetworkService string
+	// Labels - labels the connection must have to be selected by the policy.  Empty selects any labels
+	Labels map[string]string
+	// Rules - acl rules applied to the selected connections
+	Rules []*vppacl.ACL_Rule
+}
+
+func (p *Policy) selects(conn *networkservice.Connection) bool {
+	if p.NetworkService != "" && p.NetworkService != conn.GetNetworkService() {
+		return false
+	}
+	for key, value := range p.Labels {
+		if connValue, ok := conn.GetLabels()[key]; !ok || connValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// WithPolicies - applies a different acl to the connections selected by each of the policies.  Policies are tried in
+// order and the first one selecting the connection is applied.  Connections not selected by any of the policies get
+// the rules passed to NewServer.
+func WithPolicies(policies ...*Policy) Option {
+	return func(a *acl) {
+		for _, policy := range policies {
+			a.policies = append(a.policies, newACLPolicy(policy))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+)
+
+// NamedRule - an ACL rule in the key=value grammar understood by this package, along with its name and priority
+type NamedRule struct {
+	// Name - unique name of the rule
+	Name string `json:"name"`
+	// Priority - rules with a lower Priority are matched first.  Rules with equal Priority are ordered by Name
+	Priority uint32 `json:"priority"`
+	// Rule - the rule itself, for example "action=permit,dstnet=10.0.0.0/24"
+	Rule string `json:"rule"`
+}
+
+// RuleErrors - the errors for every rule that failed to parse, in rule order
+type RuleErrors []error
+
+func (e RuleErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// LoadRules converts rules to a []*vppacl.ACL_Rule
+// VPP ACLs use first match semantics, so the resulting rules are sorted by Priority and then by Name.
+// Identical input always produces identical output.  All rules are parsed even if some of them fail,
+// in which case the returned error is a RuleErrors containing an error for each failed rule.
+func LoadRules(rules []*NamedRule) ([]*vppacl.ACL_Rule, error) {
+	sorted := make([]*NamedRule, len(rules))
+	copy(sorted, rules)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority != sorted[j].Priority {
+			return sorted[i].Priority < sorted[j].Priority
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var ruleErrors RuleErrors
+	names := make(map[string]bool, len(sorted))
+	rv := make([]*vppacl.ACL_Rule, 0, len(sorted))
+	for _, namedRule := range sorted {
+		if names[namedRule.Name] {
+			ruleErrors = append(ruleErrors, errors.Errorf("rule name %q is not unique", namedRule.Name))
+			continue
+		}
+		names[namedRule.Name] = true
+		rule, err := parseRule(namedRule.Rule)
+		if err != nil {
+			ruleErrors = append(ruleErrors, errors.Errorf("parsing rule %s (%q) failed with %v", namedRule.Name, namedRule.Rule, err))
+			continue
+		}
+		rv = append(rv, rule)
+	}
+	if len(ruleErrors) > 0 {
+		return nil, ruleErrors
+	}
+	return rv, nil
+}
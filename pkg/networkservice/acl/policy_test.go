@@ -0,0 +1,101 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+)
+
+func TestLoadRules_Order(t *testing.T) {
+	rules := []*acl.NamedRule{
+		{Name: "deny-all", Priority: 100, Rule: "action=deny,dstnet=0.0.0.0/0"},
+		{Name: "b-permit", Priority: 10, Rule: "action=permit,dstnet=10.0.1.0/24"},
+		{Name: "a-permit", Priority: 10, Rule: "action=permit,dstnet=10.0.0.0/24"},
+	}
+	vppRules, err := acl.LoadRules(rules)
+	require.NoError(t, err)
+	require.Len(t, vppRules, 3)
+	assert.Equal(t, "10.0.0.0/24", vppRules[0].GetIpRule().GetIp().GetDestinationNetwork())
+	assert.Equal(t, "10.0.1.0/24", vppRules[1].GetIpRule().GetIp().GetDestinationNetwork())
+	assert.Equal(t, vppacl.ACL_Rule_DENY, vppRules[2].GetAction())
+
+	// Input order must not matter
+	reversed := []*acl.NamedRule{rules[2], rules[1], rules[0]}
+	for i := 0; i < 10; i++ {
+		again, err := acl.LoadRules(reversed)
+		require.NoError(t, err)
+		assert.Equal(t, vppRules, again)
+	}
+}
+
+func TestMapToRules_Deterministic(t *testing.T) {
+	rules := map[string]string{
+		"2":  "action=deny,dstnet=0.0.0.0/0",
+		"1":  "action=permit,tcplowport=80,tcpupport=80",
+		"10": "action=reflect,udplowport=53,udpupport=53",
+	}
+	expected, err := acl.MapToRules(rules)
+	require.NoError(t, err)
+	require.Len(t, expected, 3)
+	assert.Equal(t, vppacl.ACL_Rule_PERMIT, expected[0].GetAction())
+	assert.Equal(t, vppacl.ACL_Rule_DENY, expected[1].GetAction())
+	assert.Equal(t, vppacl.ACL_Rule_REFLECT, expected[2].GetAction())
+	for i := 0; i < 10; i++ {
+		actual, err := acl.MapToRules(rules)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestMapToRules_NamedKeys(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{
+		"b-deny":   "action=deny,dstnet=10.0.1.0/24",
+		"2":        "action=permit,dstnet=10.0.2.0/24",
+		"a-permit": "action=permit,dstnet=10.0.0.0/24",
+		"1":        "action=reflect,dstnet=10.0.3.0/24",
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 4)
+	// Numbered rules come first in the order of their keys, named rules follow ordered by name
+	assert.Equal(t, "10.0.3.0/24", rules[0].GetIpRule().GetIp().GetDestinationNetwork())
+	assert.Equal(t, "10.0.2.0/24", rules[1].GetIpRule().GetIp().GetDestinationNetwork())
+	assert.Equal(t, "10.0.0.0/24", rules[2].GetIpRule().GetIp().GetDestinationNetwork())
+	assert.Equal(t, "10.0.1.0/24", rules[3].GetIpRule().GetIp().GetDestinationNetwork())
+}
+
+func TestLoadRules_AllErrors(t *testing.T) {
+	_, err := acl.LoadRules([]*acl.NamedRule{
+		{Name: "no-action", Rule: "dstnet=10.0.0.0/24"},
+		{Name: "ok", Rule: "action=permit"},
+		{Name: "bad-upper-port", Rule: "action=permit,tcplowport=80,tcpupport=notaport"},
+		{Name: "ok", Rule: "action=deny"},
+	})
+	require.Error(t, err)
+	ruleErrors, ok := err.(acl.RuleErrors)
+	require.True(t, ok)
+	require.Len(t, ruleErrors, 3)
+	assert.Contains(t, ruleErrors[0].Error(), "bad-upper-port")
+	assert.Contains(t, ruleErrors[0].Error(), "tcpupport")
+	assert.Contains(t, ruleErrors[1].Error(), "no-action")
+	assert.Contains(t, ruleErrors[2].Error(), "not unique")
+}
@@ -16,55 +16,205 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package acl provides a NetworkServiceServer chain element to apply an ingress acl
+// Package acl provides a NetworkServiceServer chain element to apply an acl to the vpp interfaces of connections
 package acl
 
 import (
 	"context"
+	"sort"
+	"sync"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
 	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+	"google.golang.org/grpc"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/sharedconfig"
 )
 
 // ACL is a VPP Agent ACL composite
 type acl struct {
-	rules []*vppacl.ACL_Rule
+	name           string
+	direction      Direction
+	vppagentClient configurator.ConfiguratorServiceClient
+	// defaultPolicy - applied to connections not selected by any of the policies
+	defaultPolicy *aclPolicy
+	policies      []*aclPolicy
+	// connPolicies - policy applied to the connection, keyed by connection id
+	connPolicies map[string]*aclPolicy
+	mu           sync.Mutex
 }
 
-// NewServer creates a NetworkServiceServer that applies an ingress acl specified by rules
-func NewServer(rules []*vppacl.ACL_Rule) networkservice.NetworkServiceServer {
-	return &acl{rules: rules}
+// aclPolicy - state of a single shared acl
+type aclPolicy struct {
+	*Policy
+	// interfaces - vpp interface names the acl is applied to, keyed by connection id
+	interfaces map[string]string
+}
+
+// NewServer creates a NetworkServiceServer that applies an acl specified by rules
+// A single acl named 'name' (see WithName) is shared by all connections. The vpp interface of each connection is
+// added to the acl on Request and removed from it on Close.  The acl is removed once its last connection is closed.
+// Connections can be given different rules by network service and labels, see WithPolicies.
+// Requests and Closes are serialized until their config is committed, so the shared acls are committed in order.
+//             vppagentCC - grpc.ClientConnInterface of the vppagent, used to shrink the acl on Close
+//             rules - acl rules of the default policy, nil for no acl
+//             opts - options
+func NewServer(vppagentCC grpc.ClientConnInterface, rules []*vppacl.ACL_Rule, opts ...Option) networkservice.NetworkServiceServer {
+	rv := &acl{
+		name:           defaultName,
+		direction:      Ingress,
+		vppagentClient: configurator.NewConfiguratorServiceClient(vppagentCC),
+		defaultPolicy:  newACLPolicy(&Policy{Rules: rules}),
+		connPolicies:   make(map[string]*aclPolicy),
+	}
+	for _, opt := range opts {
+		opt(rv)
+	}
+	return rv
+}
+
+func newACLPolicy(policy *Policy) *aclPolicy {
+	return &aclPolicy{
+		Policy:     policy,
+		interfaces: make(map[string]string),
+	}
 }
 
 func (a *acl) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
 	conf := vppagent.Config(ctx)
-	a.appendACLConfig(conf)
-	return next.Server(ctx).Request(ctx, request)
+	if len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	connID := request.GetConnection().GetId()
+	ifaceName := conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetName()
+
+	// The lock is held until the config is committed, so that the shared acls are committed in the order they change
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// A connection keeps the policy selected by its first Request
+	policy, existed := a.connPolicies[connID]
+	if !existed {
+		policy = a.selectPolicy(request.GetConnection())
+	}
+	// Connections are tracked even while the policy has no rules, so that they get the acl once it has some, see setRules
+	a.connPolicies[connID] = policy
+	policy.interfaces[connID] = ifaceName
+	if policy.Rules != nil {
+		conf.GetVppConfig().Acls = append(conf.GetVppConfig().Acls, a.aclConfig(policy))
+	}
+
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && !existed {
+		delete(a.connPolicies, connID)
+		delete(policy.interfaces, connID)
+	}
+	return conn, err
 }
 
 func (a *acl) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	conf := vppagent.Config(ctx)
-	a.appendACLConfig(conf)
+	// The lock is held until the config is committed, see Request
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	policy, ok := a.connPolicies[conn.GetId()]
+	if !ok {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	ifaceName := policy.interfaces[conn.GetId()]
+	delete(a.connPolicies, conn.GetId())
+	delete(policy.interfaces, conn.GetId())
+	if policy.Rules == nil {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	last := len(policy.interfaces) == 0
+	err := sharedconfig.Release(ctx, a.vppagentClient, conf.GetVppConfig(), last, func(vppConfig *vpp.ConfigData) {
+		aclConfig := a.aclConfig(policy)
+		if last {
+			// Last connection, so the acl is deleted along with the rest of the connection's config
+			aclConfig.Interfaces = a.aclInterfaces([]string{ifaceName})
+		}
+		vppConfig.Acls = append(vppConfig.Acls, aclConfig)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return next.Server(ctx).Close(ctx, conn)
 }
 
-func (a *acl) appendACLConfig(conf *configurator.Config) {
-	if a.rules != nil && len(conf.GetVppConfig().GetInterfaces()) > 0 {
-		// TODO - this can likely be changed into just a single ACL, with appending new interface to which it
-		// can be applied
-		conf.GetVppConfig().Acls = append(conf.GetVppConfig().Acls, &vppacl.ACL{
-			Name:  "ingress-acl-" + conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetName(),
-			Rules: a.rules,
-			Interfaces: &vppacl.ACL_Interfaces{
-				Egress:  []string{},
-				Ingress: []string{conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetName()},
-			},
+// selectPolicy returns the first policy selecting conn, or the default policy.  Must be called with a.mu held.
+func (a *acl) selectPolicy(conn *networkservice.Connection) *aclPolicy {
+	for _, policy := range a.policies {
+		if policy.selects(conn) {
+			return policy
+		}
+	}
+	return a.defaultPolicy
+}
+
+// setRules replaces the rules of the default policy and updates its acl in the vppagent for all of the connections
+// using the default policy.  nil rules delete the acl.
+func (a *acl) setRules(ctx context.Context, rules []*vppacl.ACL_Rule) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.defaultPolicy.interfaces) == 0 {
+		a.defaultPolicy.Rules = rules
+		return nil
+	}
+	if rules == nil {
+		if a.defaultPolicy.Rules == nil {
+			return nil
+		}
+		aclConfig := a.aclConfig(a.defaultPolicy)
+		a.defaultPolicy.Rules = nil
+		return sharedconfig.Delete(ctx, a.vppagentClient, &vpp.ConfigData{
+			Acls: []*vppacl.ACL{aclConfig},
 		})
 	}
+	a.defaultPolicy.Rules = rules
+	return a.update(ctx, a.defaultPolicy)
+}
+
+// update sends the current acl of policy to the vppagent.  Must be called with a.mu held.
+func (a *acl) update(ctx context.Context, policy *aclPolicy) error {
+	return sharedconfig.Update(ctx, a.vppagentClient, &vpp.ConfigData{
+		Acls: []*vppacl.ACL{a.aclConfig(policy)},
+	})
+}
+
+// aclConfig returns the acl of policy applied to all of its current interfaces.  Must be called with a.mu held.
+func (a *acl) aclConfig(policy *aclPolicy) *vppacl.ACL {
+	ifaceNames := make([]string, 0, len(policy.interfaces))
+	for _, ifaceName := range policy.interfaces {
+		ifaceNames = append(ifaceNames, ifaceName)
+	}
+	sort.Strings(ifaceNames)
+	name := a.name
+	if policy != a.defaultPolicy {
+		name = a.name + "-" + policy.Name
+	}
+	return &vppacl.ACL{
+		Name:       name,
+		Rules:      policy.Rules,
+		Interfaces: a.aclInterfaces(ifaceNames),
+	}
+}
+
+func (a *acl) aclInterfaces(ifaceNames []string) *vppacl.ACL_Interfaces {
+	rv := &vppacl.ACL_Interfaces{
+		Egress:  []string{},
+		Ingress: []string{},
+	}
+	if a.direction&Ingress != 0 {
+		rv.Ingress = ifaceNames
+	}
+	if a.direction&Egress != 0 {
+		rv.Egress = ifaceNames
+	}
+	return rv
 }
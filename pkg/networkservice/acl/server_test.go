@@ -0,0 +1,206 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testvppagentcc"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestACLServer_SharedACL(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{"0": "action=permit,proto=tcp"})
+	require.NoError(t, err)
+
+	cc := testvppagentcc.New()
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		acl.NewServer(cc, rules, acl.WithName("test-acl"), acl.WithDirection(acl.Both)),
+		capture,
+	)
+
+	conn1, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	conn2, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "2"},
+	})
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetAcls(), 1)
+	sharedACL := capture.Config().GetVppConfig().GetAcls()[0]
+	assert.Equal(t, "test-acl", sharedACL.GetName())
+	assert.Equal(t, rules, sharedACL.GetRules())
+	assert.Equal(t, []string{"server-1", "server-2"}, sharedACL.GetInterfaces().GetIngress())
+	assert.Equal(t, []string{"server-1", "server-2"}, sharedACL.GetInterfaces().GetEgress())
+
+	// Closing a connection while others remain shrinks the acl with an Update and leaves it out of the Delete
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetAcls(), 0)
+	updates := cc.Updates()
+	require.Len(t, updates, 1)
+	require.Len(t, updates[0].GetUpdate().GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, []string{"server-2"}, updates[0].GetUpdate().GetVppConfig().GetAcls()[0].GetInterfaces().GetIngress())
+
+	// Closing the last connection deletes the acl
+	_, err = server.Close(context.Background(), conn2)
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, "test-acl", capture.Config().GetVppConfig().GetAcls()[0].GetName())
+	assert.Len(t, cc.Updates(), 1)
+}
+
+func TestACLServer_Ingress(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{"0": "action=deny"})
+	require.NoError(t, err)
+
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		acl.NewServer(testvppagentcc.New(), rules),
+		capture,
+	)
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, []string{"server-1"}, capture.Config().GetVppConfig().GetAcls()[0].GetInterfaces().GetIngress())
+	assert.Empty(t, capture.Config().GetVppConfig().GetAcls()[0].GetInterfaces().GetEgress())
+}
+
+func TestACLServer_Policies(t *testing.T) {
+	defaultRules, err := acl.MapToRules(map[string]string{"0": "action=deny"})
+	require.NoError(t, err)
+	webRules, err := acl.MapToRules(map[string]string{"0": "action=permit,proto=tcp,tcplowport=80,tcpupport=80"})
+	require.NoError(t, err)
+	dnsRules, err := acl.MapToRules(map[string]string{"0": "action=permit,proto=udp,udplowport=53,udpupport=53"})
+	require.NoError(t, err)
+
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		acl.NewServer(testvppagentcc.New(), defaultRules, acl.WithPolicies(
+			&acl.Policy{Name: "web-prod", NetworkService: "web", Labels: map[string]string{"env": "prod"}, Rules: webRules},
+			&acl.Policy{Name: "dns", NetworkService: "dns", Rules: dnsRules},
+		)),
+		capture,
+	)
+
+	for _, testCase := range []struct {
+		conn         *networkservice.Connection
+		expectedName string
+		expectedRule *vppacl.ACL_Rule
+	}{
+		{
+			conn:         &networkservice.Connection{Id: "1", NetworkService: "web", Labels: map[string]string{"env": "prod", "app": "a"}},
+			expectedName: "nsm-acl-web-prod",
+			expectedRule: webRules[0],
+		},
+		{
+			conn:         &networkservice.Connection{Id: "2", NetworkService: "web", Labels: map[string]string{"env": "dev"}},
+			expectedName: "nsm-acl",
+			expectedRule: defaultRules[0],
+		},
+		{
+			conn:         &networkservice.Connection{Id: "3", NetworkService: "dns"},
+			expectedName: "nsm-acl-dns",
+			expectedRule: dnsRules[0],
+		},
+	} {
+		_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: testCase.conn})
+		require.NoError(t, err)
+		acls := capture.Config().GetVppConfig().GetAcls()
+		require.Len(t, acls, 1)
+		assert.Equal(t, testCase.expectedName, acls[0].GetName())
+		assert.Equal(t, []*vppacl.ACL_Rule{testCase.expectedRule}, acls[0].GetRules())
+		assert.Equal(t, []string{"server-" + testCase.conn.GetId()}, acls[0].GetInterfaces().GetIngress())
+	}
+}
+
+type blockingServer struct {
+	requests chan string
+	release  chan struct{}
+}
+
+func (b *blockingServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	b.requests <- request.GetConnection().GetId()
+	<-b.release
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (b *blockingServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func TestACLServer_SerializedCommits(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{"0": "action=permit,proto=tcp"})
+	require.NoError(t, err)
+
+	blocking := &blockingServer{
+		requests: make(chan string, 2),
+		release:  make(chan struct{}),
+	}
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		acl.NewServer(testvppagentcc.New(), rules),
+		blocking,
+	)
+
+	errs := make(chan error, 2)
+	for _, id := range []string{"1", "2"} {
+		go func(id string) {
+			_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+				Connection: &networkservice.Connection{Id: id},
+			})
+			errs <- err
+		}(id)
+	}
+
+	// The second Request does not get past the acl until the first one is committed
+	first := <-blocking.requests
+	select {
+	case id := <-blocking.requests:
+		close(blocking.release)
+		require.Failf(t, "concurrent commit", "Request %s committed while Request %s is committing", id, first)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(blocking.release)
+	assert.NotEqual(t, first, <-blocking.requests)
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+}
@@ -17,7 +17,9 @@
 package acl
 
 import (
+	"math"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -26,36 +28,139 @@ import (
 )
 
 const (
-	action     = "action"     // DENY, PERMIT, REFLECT
-	dstNet     = "dstnet"     // IPv4 or IPv6 CIDR
-	srcNet     = "srcnet"     // IPv4 or IPv6 CIDR
-	icmpType   = "icmptype"   // 8-bit unsigned integer
-	tcpLowPort = "tcplowport" // 16-bit unsigned integer
-	tcpUpPort  = "tcpupport"  // 16-bit unsigned integer
-	udpLowPort = "udplowport" // 16-bit unsigned integer
-	udpUpPort  = "udpupport"  // 16-bit unsigned integer
+	action        = "action"        // DENY, PERMIT, REFLECT
+	dstNet        = "dstnet"        // IPv4 or IPv6 CIDR
+	srcNet        = "srcnet"        // IPv4 or IPv6 CIDR
+	proto         = "proto"         // icmp, tcp, udp, icmpv6 or the matching IP protocol number (1, 6, 17, 58)
+	icmpType      = "icmptype"      // 8-bit unsigned integer or range of them, for example 0-10
+	icmpCode      = "icmpcode"      // 8-bit unsigned integer or range of them, for example 0-10
+	icmpv6        = "icmpv6"        // true or false
+	tcpLowPort    = "tcplowport"    // 16-bit unsigned integer
+	tcpUpPort     = "tcpupport"     // 16-bit unsigned integer
+	tcpSrcLowPort = "tcpsrclowport" // 16-bit unsigned integer
+	tcpSrcUpPort  = "tcpsrcupport"  // 16-bit unsigned integer
+	tcpFlagsMask  = "tcpflagsmask"  // 8-bit unsigned integer
+	tcpFlagsValue = "tcpflagsvalue" // 8-bit unsigned integer
+	udpLowPort    = "udplowport"    // 16-bit unsigned integer
+	udpUpPort     = "udpupport"     // 16-bit unsigned integer
+	udpSrcLowPort = "udpsrclowport" // 16-bit unsigned integer
+	udpSrcUpPort  = "udpsrcupport"  // 16-bit unsigned integer
+	srcMac        = "srcmac"        // MAC address, makes the rule a MAC-IP rule
+	srcMacMask    = "srcmacmask"    // MAC address mask, defaults to ff:ff:ff:ff:ff:ff
+)
+
+const (
+	protoICMP   = "icmp"
+	protoTCP    = "tcp"
+	protoUDP    = "udp"
+	protoICMPv6 = "icmpv6"
+
+	anyFirst = 0
+	anyLast  = 65535
+	// anyICMPLast is the upper bound of ICMP and ICMPv6 type and code ranges, which are 8-bit
+	anyICMPLast = 255
+
+	// namedRulePriority is the priority MapToRules gives to rules whose key is not an unsigned integer
+	namedRulePriority = math.MaxUint32
+
+	defaultMacMask = "ff:ff:ff:ff:ff:ff"
+)
+
+var (
+	protoNumbers = map[string]string{
+		"1":  protoICMP,
+		"6":  protoTCP,
+		"17": protoUDP,
+		"58": protoICMPv6,
+	}
+	icmpKeys = []string{icmpType, icmpCode, icmpv6}
+	tcpKeys  = []string{tcpLowPort, tcpUpPort, tcpSrcLowPort, tcpSrcUpPort, tcpFlagsMask, tcpFlagsValue}
+	udpKeys  = []string{udpLowPort, udpUpPort, udpSrcLowPort, udpSrcUpPort}
+	// ipKeys are the keys that only apply to IP rules, as opposed to MAC-IP rules
+	ipKeys = concat([]string{dstNet, proto}, icmpKeys, tcpKeys, udpKeys)
+	// knownKeys are all of the keys of the grammar
+	knownKeys = concat([]string{action, srcNet, srcMac, srcMacMask}, ipKeys)
 )
 
 // MapToRules converts a map[string]string of rules to a []*vppacl.ACL_Rule
+// The map key is used as the rule name.  Keys that are unsigned integers are also used as the rule priority, so
+// that the resulting order is deterministic and follows the numbering of the keys.  Any other key ("allow-dns" for
+// example) gets the lowest priority (namedRulePriority): those rules follow all of the numbered ones, ordered by
+// name.  See LoadRules for details.
 func MapToRules(rules map[string]string) ([]*vppacl.ACL_Rule, error) {
-	rv := []*vppacl.ACL_Rule{}
-	for _, rule := range rules {
-		parsed := parseKVStringToMap(rule, ",", "=")
-
-		action, err := getAction(parsed)
+	namedRules := make([]*NamedRule, 0, len(rules))
+	for name, rule := range rules {
+		priority, err := strconv.ParseUint(name, 10, 32)
 		if err != nil {
-			return nil, errors.Errorf("parsing rule %s failed with %v", rule, err)
+			priority = namedRulePriority
 		}
+		namedRules = append(namedRules, &NamedRule{
+			Name:     name,
+			Priority: uint32(priority),
+			Rule:     rule,
+		})
+	}
+	return LoadRules(namedRules)
+}
 
-		match, err := getMatch(parsed)
-		if err != nil {
-			return nil, errors.Errorf("parsing rule %s failed with %v", rule, err)
+func parseRule(rule string) (*vppacl.ACL_Rule, error) {
+	parsed := parseKVStringToMap(rule, ",", "=")
+	if err := checkKeys(parsed); err != nil {
+		return nil, err
+	}
+
+	action, err := getAction(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := getMatch(parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	match.Action = action
+	return match, nil
+}
+
+func concat(keys ...[]string) []string {
+	var rv []string
+	for _, k := range keys {
+		rv = append(rv, k...)
+	}
+	return rv
+}
+
+func checkKeys(parsed map[string]string) error {
+	var unknown []string
+	for key := range parsed {
+		if key != "" && !isKnownKey(key) {
+			unknown = append(unknown, key)
 		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return errors.Errorf("unknown keys %q", unknown)
+}
 
-		match.Action = action
-		rv = append(rv, match)
+func isKnownKey(key string) bool {
+	for _, knownKey := range knownKeys {
+		if key == knownKey {
+			return true
+		}
 	}
-	return rv, nil
+	return false
+}
+
+func hasAnyKey(parsed map[string]string, keys ...string) bool {
+	for _, key := range keys {
+		if _, ok := parsed[key]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func getAction(parsed map[string]string) (vppacl.ACL_Rule_Action, error) {
@@ -70,6 +175,22 @@ func getAction(parsed map[string]string) (vppacl.ACL_Rule_Action, error) {
 	return vppacl.ACL_Rule_Action(action), nil
 }
 
+func getProto(parsed map[string]string) (string, error) {
+	protoName, ok := parsed[proto]
+	if !ok {
+		return "", nil
+	}
+	protoName = strings.ToLower(protoName)
+	if name, ok := protoNumbers[protoName]; ok {
+		return name, nil
+	}
+	switch protoName {
+	case protoICMP, protoTCP, protoUDP, protoICMPv6:
+		return protoName, nil
+	}
+	return "", errors.Errorf("proto [%v] is not supported, must be one of icmp, tcp, udp, icmpv6 (or 1, 6, 17, 58)", protoName)
+}
+
 func getIP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Ip, error) {
 	dstNet, dstNetOk := parsed[dstNet]
 	srcNet, srcNetOk := parsed[srcNet]
@@ -100,25 +221,60 @@ func getIP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Ip, error) {
 	return nil, nil
 }
 
-func getICMP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Icmp, error) {
-	icmpType, ok := parsed[icmpType]
+// getRange parses either a single 8-bit value or a 'first-last' range of them.  Unset ranges match anything.
+func getRange(name string, parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Icmp_Range, error) {
+	rangeString, ok := parsed[name]
 	if !ok {
-		return nil, nil
+		return &vppacl.ACL_Rule_IpRule_Icmp_Range{
+			First: uint32(anyFirst),
+			Last:  uint32(anyICMPLast),
+		}, nil
+	}
+	bounds := strings.SplitN(rangeString, "-", 2)
+	first, err := strconv.ParseUint(strings.TrimSpace(bounds[0]), 10, 8)
+	if err != nil {
+		return nil, errors.Errorf("failed parsing %s [%v] with: %v", name, rangeString, err)
+	}
+	last := first
+	if len(bounds) == 2 {
+		last, err = strconv.ParseUint(strings.TrimSpace(bounds[1]), 10, 8)
+		if err != nil {
+			return nil, errors.Errorf("failed parsing %s [%v] with: %v", name, rangeString, err)
+		}
 	}
-	icmpType8, err := strconv.ParseUint(icmpType, 10, 8)
+	if first > last {
+		return nil, errors.Errorf("%s [%v] is not a valid range", name, rangeString)
+	}
+	return &vppacl.ACL_Rule_IpRule_Icmp_Range{
+		First: uint32(first),
+		Last:  uint32(last),
+	}, nil
+}
+
+func getICMP(parsed map[string]string, protoName string) (*vppacl.ACL_Rule_IpRule_Icmp, error) {
+	isICMPv6 := protoName == protoICMPv6
+	if icmpv6String, ok := parsed[icmpv6]; ok {
+		v6, err := strconv.ParseBool(icmpv6String)
+		if err != nil {
+			return nil, errors.Errorf("failed parsing icmpv6 [%v] with: %v", icmpv6String, err)
+		}
+		if _, explicit := parsed[proto]; explicit && (protoName == protoICMP && v6 || protoName == protoICMPv6 && !v6) {
+			return nil, errors.Errorf("icmpv6 [%v] contradicts proto [%v]", icmpv6String, protoName)
+		}
+		isICMPv6 = v6
+	}
+	typeRange, err := getRange(icmpType, parsed)
 	if err != nil {
-		return nil, errors.Errorf("failed parsing icmptype [%v] with: %v", icmpType, err)
+		return nil, err
+	}
+	codeRange, err := getRange(icmpCode, parsed)
+	if err != nil {
+		return nil, err
 	}
 	return &vppacl.ACL_Rule_IpRule_Icmp{
-		Icmpv6: false,
-		IcmpCodeRange: &vppacl.ACL_Rule_IpRule_Icmp_Range{
-			First: uint32(0),
-			Last:  uint32(65535),
-		},
-		IcmpTypeRange: &vppacl.ACL_Rule_IpRule_Icmp_Range{
-			First: uint32(icmpType8),
-			Last:  uint32(icmpType8),
-		},
+		Icmpv6:        isICMPv6,
+		IcmpCodeRange: codeRange,
+		IcmpTypeRange: typeRange,
 	}, nil
 }
 
@@ -135,92 +291,192 @@ func getPort(name string, parsed map[string]string) (port uint16, found bool, er
 	return uint16(port16), true, nil
 }
 
-func getTCP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Tcp, error) {
-	lowerPort, lpFound, lpErr := getPort(tcpLowPort, parsed)
-	if !lpFound {
-		return nil, nil
-	} else if lpErr != nil {
+// getPortRange parses a port range from the lowName and upName keys.  A missing bound leaves that end of the range open.
+func getPortRange(lowName, upName string, parsed map[string]string) (*vppacl.ACL_Rule_IpRule_PortRange, error) {
+	lowerPort, lpFound, lpErr := getPort(lowName, parsed)
+	if lpErr != nil {
 		return nil, lpErr
+	} else if !lpFound {
+		lowerPort = anyFirst
 	}
 
-	upperPort, upFound, upErr := getPort(tcpUpPort, parsed)
-	if !upFound {
-		return nil, nil
-	} else if upErr != nil {
-		return nil, lpErr
+	upperPort, upFound, upErr := getPort(upName, parsed)
+	if upErr != nil {
+		return nil, upErr
+	} else if !upFound {
+		upperPort = anyLast
+	}
+
+	if lowerPort > upperPort {
+		return nil, errors.Errorf("%s [%d] is greater than %s [%d]", lowName, lowerPort, upName, upperPort)
+	}
+	return &vppacl.ACL_Rule_IpRule_PortRange{
+		LowerPort: uint32(lowerPort),
+		UpperPort: uint32(upperPort),
+	}, nil
+}
+
+func getTCPFlags(parsed map[string]string) (mask, value uint32, err error) {
+	for _, flags := range []struct {
+		name string
+		rv   *uint32
+	}{{tcpFlagsMask, &mask}, {tcpFlagsValue, &value}} {
+		flagsString, ok := parsed[flags.name]
+		if !ok {
+			continue
+		}
+		flags8, parseErr := strconv.ParseUint(flagsString, 10, 8)
+		if parseErr != nil {
+			return 0, 0, errors.Errorf("failed parsing %s [%v] with: %v", flags.name, flagsString, parseErr)
+		}
+		*flags.rv = uint32(flags8)
+	}
+	if value&^mask != 0 {
+		return 0, 0, errors.Errorf("%s [%d] sets flags outside of %s [%d]", tcpFlagsValue, value, tcpFlagsMask, mask)
+	}
+	return mask, value, nil
+}
+
+func getTCP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Tcp, error) {
+	dstRange, err := getPortRange(tcpLowPort, tcpUpPort, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	srcRange, err := getPortRange(tcpSrcLowPort, tcpSrcUpPort, parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	mask, value, err := getTCPFlags(parsed)
+	if err != nil {
+		return nil, err
 	}
 
 	return &vppacl.ACL_Rule_IpRule_Tcp{
-		DestinationPortRange: &vppacl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(lowerPort),
-			UpperPort: uint32(upperPort),
-		},
-		SourcePortRange: &vppacl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(0),
-			UpperPort: uint32(65535),
-		},
-		TcpFlagsMask:  0,
-		TcpFlagsValue: 0,
+		DestinationPortRange: dstRange,
+		SourcePortRange:      srcRange,
+		TcpFlagsMask:         mask,
+		TcpFlagsValue:        value,
 	}, nil
 }
 
 func getUDP(parsed map[string]string) (*vppacl.ACL_Rule_IpRule_Udp, error) {
-	lowerPort, lpFound, lpErr := getPort(udpLowPort, parsed)
-	if !lpFound {
-		return nil, nil
-	} else if lpErr != nil {
-		return nil, lpErr
+	dstRange, err := getPortRange(udpLowPort, udpUpPort, parsed)
+	if err != nil {
+		return nil, err
 	}
 
-	upperPort, upFound, upErr := getPort(udpUpPort, parsed)
-	if !upFound {
-		return nil, nil
-	} else if upErr != nil {
-		return nil, lpErr
+	srcRange, err := getPortRange(udpSrcLowPort, udpSrcUpPort, parsed)
+	if err != nil {
+		return nil, err
 	}
 
 	return &vppacl.ACL_Rule_IpRule_Udp{
-		DestinationPortRange: &vppacl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(lowerPort),
-			UpperPort: uint32(upperPort),
-		},
-		SourcePortRange: &vppacl.ACL_Rule_IpRule_PortRange{
-			LowerPort: uint32(0),
-			UpperPort: uint32(65535),
-		},
+		DestinationPortRange: dstRange,
+		SourcePortRange:      srcRange,
 	}, nil
 }
 
+// getL4Proto returns the protocol matched by the rule, either set explicitly with 'proto' or implied by the other keys
+func getL4Proto(parsed map[string]string) (string, error) {
+	protoName, err := getProto(parsed)
+	if err != nil {
+		return "", err
+	}
+	for _, implied := range []struct {
+		name string
+		keys []string
+	}{{protoICMP, icmpKeys}, {protoTCP, tcpKeys}, {protoUDP, udpKeys}} {
+		if !hasAnyKey(parsed, implied.keys...) {
+			continue
+		}
+		switch {
+		case protoName == "":
+			protoName = implied.name
+		case protoName == implied.name, protoName == protoICMPv6 && implied.name == protoICMP:
+		default:
+			return "", errors.Errorf("a rule can match only one of icmp, tcp or udp, got keys for both %s and %s", protoName, implied.name)
+		}
+	}
+	return protoName, nil
+}
+
 func getIPRule(parsed map[string]string) (*vppacl.ACL_Rule_IpRule, error) {
 	ip, err := getIP(parsed)
 	if err != nil {
 		return nil, err
 	}
 
-	icmp, err := getICMP(parsed)
+	protoName, err := getL4Proto(parsed)
 	if err != nil {
 		return nil, err
 	}
 
-	tcp, err := getTCP(parsed)
+	rv := &vppacl.ACL_Rule_IpRule{
+		Ip: ip,
+	}
+	switch protoName {
+	case protoICMP, protoICMPv6:
+		rv.Icmp, err = getICMP(parsed, protoName)
+	case protoTCP:
+		rv.Tcp, err = getTCP(parsed)
+	case protoUDP:
+		rv.Udp, err = getUDP(parsed)
+	}
 	if err != nil {
 		return nil, err
 	}
+	return rv, nil
+}
 
-	udp, err := getUDP(parsed)
+func getMacIPRule(parsed map[string]string) (*vppacl.ACL_Rule_MacIpRule, error) {
+	if hasAnyKey(parsed, ipKeys...) {
+		return nil, errors.Errorf("srcmac can only be combined with action, srcnet and srcmacmask")
+	}
+	srcNetString, ok := parsed[srcNet]
+	if !ok {
+		return nil, errors.New("srcmac requires srcnet to be set")
+	}
+	srcIP, srcIPNet, err := net.ParseCIDR(srcNetString)
 	if err != nil {
-		return nil, err
+		return nil, errors.Errorf("srcnet is not a valid CIDR [%v]. Failed with: %v", srcNetString, err)
 	}
-
-	return &vppacl.ACL_Rule_IpRule{
-		Ip:   ip,
-		Icmp: icmp,
-		Tcp:  tcp,
-		Udp:  udp,
+	prefixLength, _ := srcIPNet.Mask.Size()
+	mac, err := net.ParseMAC(parsed[srcMac])
+	if err != nil {
+		return nil, errors.Errorf("failed parsing srcmac [%v] with: %v", parsed[srcMac], err)
+	}
+	macMaskString, ok := parsed[srcMacMask]
+	if !ok {
+		macMaskString = defaultMacMask
+	}
+	macMask, err := net.ParseMAC(macMaskString)
+	if err != nil {
+		return nil, errors.Errorf("failed parsing srcmacmask [%v] with: %v", macMaskString, err)
+	}
+	return &vppacl.ACL_Rule_MacIpRule{
+		SourceAddress:        srcIP.String(),
+		SourceAddressPrefix:  uint32(prefixLength),
+		SourceMacAddress:     mac.String(),
+		SourceMacAddressMask: macMask.String(),
 	}, nil
 }
 
 func getMatch(parsed map[string]string) (*vppacl.ACL_Rule, error) {
+	if _, ok := parsed[srcMac]; ok {
+		macIPRule, err := getMacIPRule(parsed)
+		if err != nil {
+			return nil, err
+		}
+		return &vppacl.ACL_Rule{
+			MacipRule: macIPRule,
+		}, nil
+	}
+	if _, ok := parsed[srcMacMask]; ok {
+		return nil, errors.New("srcmacmask requires srcmac to be set")
+	}
+
 	ipRule, err := getIPRule(parsed)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,119 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+)
+
+// RulesFile - contents of an acl rules file
+//
+// Rules files are written in YAML or JSON, for example:
+//     rules:
+//       - name: allow-dns
+//         priority: 10
+//         rule: action=permit,proto=udp,udplowport=53,udpupport=53
+//       - name: deny-all
+//         priority: 100
+//         rule: action=deny
+type RulesFile struct {
+	Rules []*NamedRule `json:"rules"`
+}
+
+// ParseRulesFile parses contents of a RulesFile and converts its rules with LoadRules
+// A RulesFile without rules, an empty one for example, means no acl and returns nil rules.  An empty list of rules
+// ("rules: []") is an acl denying everything.
+func ParseRulesFile(contents []byte) ([]*vppacl.ACL_Rule, error) {
+	rulesFile := &RulesFile{}
+	if err := yaml.Unmarshal(contents, rulesFile); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal acl rules file")
+	}
+	if rulesFile.Rules == nil {
+		return nil, nil
+	}
+	return LoadRules(rulesFile.Rules)
+}
+
+// WithRulesFile - loads the acl rules of the default policy from filename, a RulesFile, and polls it for changes every
+// pollInterval until ctx is done.  Changed rules are validated before they are applied, and then the acl is updated in the vppagent for
+// all of the connections using the default policy, including those made while there was no acl.  If the changed rules
+// are invalid, the current rules are kept.  A file without rules removes the acl, see ParseRulesFile.
+func WithRulesFile(ctx context.Context, filename string, pollInterval time.Duration) Option {
+	return func(a *acl) {
+		w := &rulesFileWatcher{
+			acl:      a,
+			filename: filename,
+		}
+		w.reload(ctx)
+		go w.watch(ctx, pollInterval)
+	}
+}
+
+type rulesFileWatcher struct {
+	acl      *acl
+	filename string
+	contents []byte
+}
+
+func (w *rulesFileWatcher) watch(ctx context.Context, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.reload(ctx)
+		}
+	}
+}
+
+func (w *rulesFileWatcher) reload(ctx context.Context) {
+	contents, err := ioutil.ReadFile(w.filename)
+	if err != nil {
+		log.Entry(ctx).Errorf("failed to read acl rules file %q, keeping the current rules: %+v", w.filename, err)
+		return
+	}
+	if w.contents != nil && bytes.Equal(contents, w.contents) {
+		return
+	}
+	w.contents = contents
+	rules, err := ParseRulesFile(contents)
+	if err != nil {
+		log.Entry(ctx).Errorf("invalid acl rules file %q, keeping the current rules: %+v", w.filename, err)
+		return
+	}
+	if err := w.acl.setRules(ctx, rules); err != nil {
+		log.Entry(ctx).Errorf("failed to apply the rules of acl rules file %q: %+v", w.filename, err)
+		// Try again on the next poll
+		w.contents = nil
+		return
+	}
+	if rules == nil {
+		log.Entry(ctx).Infof("acl rules file %q has no rules, no acl is applied", w.filename)
+		return
+	}
+	log.Entry(ctx).Infof("applied %d rules from acl rules file %q", len(rules), w.filename)
+}
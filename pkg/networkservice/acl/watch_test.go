@@ -0,0 +1,163 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package acl_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	vppacl "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/acl"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testvppagentcc"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	permitRulesFile = `
+rules:
+  - name: permit-tcp
+    priority: 10
+    rule: action=permit,proto=tcp
+`
+	denyRulesFile    = `{"rules": [{"name": "deny-all", "priority": 100, "rule": "action=deny"}]}`
+	invalidRulesFile = `
+rules:
+  - name: invalid
+    rule: action=maybe
+`
+)
+
+func TestParseRulesFile(t *testing.T) {
+	rules, err := acl.ParseRulesFile([]byte(permitRulesFile))
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.NotNil(t, rules[0].GetIpRule().GetTcp())
+
+	rules, err = acl.ParseRulesFile([]byte(denyRulesFile))
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, vppacl.ACL_Rule_DENY, rules[0].GetAction())
+
+	_, err = acl.ParseRulesFile([]byte(invalidRulesFile))
+	assert.Error(t, err)
+
+	// No rules means no acl, an empty list of rules an acl denying everything
+	rules, err = acl.ParseRulesFile([]byte(" \n"))
+	require.NoError(t, err)
+	assert.Nil(t, rules)
+	rules, err = acl.ParseRulesFile([]byte("rules: []"))
+	require.NoError(t, err)
+	assert.NotNil(t, rules)
+	assert.Len(t, rules, 0)
+}
+
+func TestWithRulesFile(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	filename := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(permitRulesFile), 0600))
+
+	cc := testvppagentcc.New()
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		acl.NewServer(cc, nil, acl.WithRulesFile(ctx, filename, 10*time.Millisecond)),
+		capture,
+	)
+	request := &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	}
+	_, err = server.Request(context.Background(), request)
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetAcls(), 1)
+	assert.Equal(t, vppacl.ACL_Rule_PERMIT, capture.Config().GetVppConfig().GetAcls()[0].GetRules()[0].GetAction())
+
+	// Valid changes are applied to the live connections
+	require.NoError(t, ioutil.WriteFile(filename, []byte(denyRulesFile), 0600))
+	require.Eventually(t, func() bool { return len(cc.Updates()) == 1 }, time.Second, 10*time.Millisecond)
+	updatedACL := cc.Updates()[0].GetUpdate().GetVppConfig().GetAcls()[0]
+	assert.Equal(t, vppacl.ACL_Rule_DENY, updatedACL.GetRules()[0].GetAction())
+	assert.Equal(t, []string{"server-1"}, updatedACL.GetInterfaces().GetIngress())
+
+	// Invalid changes are ignored
+	require.NoError(t, ioutil.WriteFile(filename, []byte(invalidRulesFile), 0600))
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, cc.Updates(), 1)
+	_, err = server.Request(context.Background(), request)
+	require.NoError(t, err)
+	assert.Equal(t, vppacl.ACL_Rule_DENY, capture.Config().GetVppConfig().GetAcls()[0].GetRules()[0].GetAction())
+}
+
+func TestWithRulesFile_NoRules(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "acl")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	filename := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, ioutil.WriteFile(filename, []byte(""), 0600))
+
+	cc := testvppagentcc.New()
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		acl.NewServer(cc, nil, acl.WithRulesFile(ctx, filename, 10*time.Millisecond)),
+		capture,
+	)
+	conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetAcls(), 0)
+
+	// Connections made while there were no rules get the acl once the rules are loaded
+	require.NoError(t, ioutil.WriteFile(filename, []byte(permitRulesFile), 0600))
+	require.Eventually(t, func() bool { return len(cc.Updates()) == 1 }, time.Second, 10*time.Millisecond)
+	updatedACL := cc.Updates()[0].GetUpdate().GetVppConfig().GetAcls()[0]
+	assert.Equal(t, vppacl.ACL_Rule_PERMIT, updatedACL.GetRules()[0].GetAction())
+	assert.Equal(t, []string{"server-1"}, updatedACL.GetInterfaces().GetIngress())
+
+	// Removing the rules removes the acl rather than denying everything
+	require.NoError(t, ioutil.WriteFile(filename, []byte("  \n"), 0600))
+	require.Eventually(t, func() bool { return len(cc.Deletes()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Len(t, cc.Updates(), 1)
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetAcls(), 0)
+}
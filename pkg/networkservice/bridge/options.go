@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridge
+
+// Option - option for bridge.NewServer
+type Option func(b *bridgeServer)
+
+// WithFlood - enables or disables flooding of broadcast and multicast packets.  Disabled by default
+func WithFlood(flood bool) Option {
+	return func(b *bridgeServer) {
+		b.settings.Flood = flood
+	}
+}
+
+// WithUnknownUnicastFlood - enables or disables flooding of unicast packets with an unknown destination.  Disabled by
+// default
+func WithUnknownUnicastFlood(unknownUnicastFlood bool) Option {
+	return func(b *bridgeServer) {
+		b.settings.UnknownUnicastFlood = unknownUnicastFlood
+	}
+}
+
+// WithForward - enables or disables forwarding of unicast packets.  Enabled by default
+func WithForward(forward bool) Option {
+	return func(b *bridgeServer) {
+		b.settings.Forward = forward
+	}
+}
+
+// WithLearn - enables or disables learning of mac addresses.  Enabled by default
+func WithLearn(learn bool) Option {
+	return func(b *bridgeServer) {
+		b.settings.Learn = learn
+	}
+}
+
+// WithArpTermination - enables or disables answering of arp requests by the bridge.  When enabled, the arp termination
+// table is populated from the src IP and MAC of each connection.  Disabled by default
+func WithArpTermination(arpTermination bool) Option {
+	return func(b *bridgeServer) {
+		b.settings.ArpTermination = arpTermination
+	}
+}
+
+// WithMacAge - sets the time in minutes after which learned mac addresses are removed.  0 (the default) disables aging
+func WithMacAge(macAge uint32) Option {
+	return func(b *bridgeServer) {
+		b.settings.MacAge = macAge
+	}
+}
+
+// WithBVI - adds a bridged virtual interface (BVI) with ipAddresses (in CIDR notation) to the bridge, so that the
+// bridge can act as the gateway for the connections plugged into it
+func WithBVI(ipAddresses ...string) Option {
+	return func(b *bridgeServer) {
+		b.bviIPAddresses = append([]string{}, ipAddresses...)
+	}
+}
@@ -19,53 +19,194 @@ package bridge
 
 import (
 	"context"
+	"net"
+	"sort"
+	"sync"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
+	"google.golang.org/grpc"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/sharedconfig"
 )
 
 type bridgeServer struct {
-	name string
+	name           string
+	settings       *l2.BridgeDomain
+	bviIPAddresses []string
+	vppagentClient configurator.ConfiguratorServiceClient
+	// members - connections plugged into the bridge, keyed by connection id
+	members map[string]*member
+	mu      sync.Mutex
+}
+
+type member struct {
+	ifaceName string
+	// arpEntries - arp termination entries for the connection's src IPs and MAC, empty if unknown or not needed
+	arpEntries []*l2.BridgeDomain_ArpTerminationEntry
 }
 
 // NewServer creates a NetworkServiceServer that will plug an incoming vWire into a bridge named 'name'
-func NewServer(name string) networkservice.NetworkServiceServer {
-	return &bridgeServer{name: name}
+// The bridge domain is shared by all connections.  The vpp interface of each connection is added to it on Request and
+// removed from it on Close.  The bridge domain is removed once its last connection is closed.
+// If arp termination is enabled, the src IPs and MAC of each connection are added to the arp termination table.
+// Requests and Closes are serialized until their config is committed, so the bridge domain is committed in order.
+// The BVI (see WithBVI) is appended after the vpp interface of the connection, so elements taking the last vpp
+// interface as the connection's one must come before the bridge in the chain.
+//             vppagentCC - grpc.ClientConnInterface of the vppagent, used to shrink the bridge domain on Close
+//             name - name of the bridge domain
+//             opts - options
+func NewServer(vppagentCC grpc.ClientConnInterface, name string, opts ...Option) networkservice.NetworkServiceServer {
+	rv := &bridgeServer{
+		name: name,
+		settings: &l2.BridgeDomain{
+			Flood:               false,
+			UnknownUnicastFlood: false,
+			Forward:             true,
+			Learn:               true,
+			ArpTermination:      false,
+		},
+		vppagentClient: configurator.NewConfiguratorServiceClient(vppagentCC),
+		members:        make(map[string]*member),
+	}
+	for _, opt := range opts {
+		opt(rv)
+	}
+	return rv
 }
 
 func (b *bridgeServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
 	conf := vppagent.Config(ctx)
-	b.insertInterfaceIntoBridge(conf)
-	return next.Server(ctx).Request(ctx, request)
+	if len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	connID := request.GetConnection().GetId()
+	ifaceName := conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetName()
+
+	// The lock is held until the config is committed, so that the bridge domain is committed in the order it changes
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, existed := b.members[connID]
+	b.members[connID] = &member{
+		ifaceName:  ifaceName,
+		arpEntries: b.arpEntries(request.GetConnection()),
+	}
+	b.appendBridgeConfig(conf.GetVppConfig(), b.bridgeDomain(b.sortedMembers()))
+
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && !existed {
+		delete(b.members, connID)
+	}
+	return conn, err
 }
 
 func (b *bridgeServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	conf := vppagent.Config(ctx)
-	b.insertInterfaceIntoBridge(conf)
+	// The lock is held until the config is committed, see Request
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.members[conn.GetId()]
+	if !ok {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	delete(b.members, conn.GetId())
+	last := len(b.members) == 0
+	err := sharedconfig.Release(ctx, b.vppagentClient, conf.GetVppConfig(), last, func(vppConfig *vpp.ConfigData) {
+		if last {
+			// Last connection, so the bridge domain is deleted along with the rest of the connection's config
+			b.appendBridgeConfig(vppConfig, b.bridgeDomain([]*member{m}))
+			return
+		}
+		b.appendBridgeConfig(vppConfig, b.bridgeDomain(b.sortedMembers()))
+	})
+	if err != nil {
+		return nil, err
+	}
 	return next.Server(ctx).Close(ctx, conn)
 }
 
-func (b *bridgeServer) insertInterfaceIntoBridge(conf *configurator.Config) {
-	if len(conf.GetVppConfig().GetInterfaces()) > 0 {
-		conf.GetVppConfig().BridgeDomains = append(conf.GetVppConfig().BridgeDomains, &l2.BridgeDomain{
-			Name:                b.name,
-			Flood:               false,
-			UnknownUnicastFlood: false,
-			Forward:             true,
-			Learn:               true,
-			ArpTermination:      false,
-			Interfaces: []*l2.BridgeDomain_Interface{
-				{
-					Name:                    conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].GetName(),
-					BridgedVirtualInterface: false,
-				},
-			},
+func (b *bridgeServer) appendBridgeConfig(vppConfig *vpp.ConfigData, bridgeDomain *l2.BridgeDomain) {
+	if b.bviIPAddresses != nil {
+		vppConfig.Interfaces = append(vppConfig.Interfaces, &vpp.Interface{
+			Name:        b.bviName(),
+			Type:        vppinterfaces.Interface_SOFTWARE_LOOPBACK,
+			Enabled:     true,
+			IpAddresses: b.bviIPAddresses,
+		})
+	}
+	vppConfig.BridgeDomains = append(vppConfig.BridgeDomains, bridgeDomain)
+}
+
+// sortedMembers returns the members of the bridge sorted by interface name.  Must be called with b.mu held.
+func (b *bridgeServer) sortedMembers() []*member {
+	members := make([]*member, 0, len(b.members))
+	for _, m := range b.members {
+		members = append(members, m)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].ifaceName < members[j].ifaceName
+	})
+	return members
+}
+
+// arpEntries returns an arp termination entry for each of the src addresses of conn, see ipaddrs for dual-stack
+// addresses
+func (b *bridgeServer) arpEntries(conn *networkservice.Connection) []*l2.BridgeDomain_ArpTerminationEntry {
+	if !b.settings.GetArpTermination() {
+		return nil
+	}
+	srcMac := conn.GetContext().GetEthernetContext().GetSrcMac()
+	if srcMac == "" {
+		return nil
+	}
+	var rv []*l2.BridgeDomain_ArpTerminationEntry
+	for _, srcIPAddr := range ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr()) {
+		srcIP, _, err := net.ParseCIDR(srcIPAddr)
+		if err != nil {
+			continue
+		}
+		rv = append(rv, &l2.BridgeDomain_ArpTerminationEntry{
+			IpAddress:   srcIP.String(),
+			PhysAddress: srcMac,
 		})
 	}
+	return rv
+}
+
+func (b *bridgeServer) bridgeDomain(members []*member) *l2.BridgeDomain {
+	rv := &l2.BridgeDomain{
+		Name:                b.name,
+		Flood:               b.settings.GetFlood(),
+		UnknownUnicastFlood: b.settings.GetUnknownUnicastFlood(),
+		Forward:             b.settings.GetForward(),
+		Learn:               b.settings.GetLearn(),
+		ArpTermination:      b.settings.GetArpTermination(),
+		MacAge:              b.settings.GetMacAge(),
+	}
+	if b.bviIPAddresses != nil {
+		rv.Interfaces = append(rv.Interfaces, &l2.BridgeDomain_Interface{
+			Name:                    b.bviName(),
+			BridgedVirtualInterface: true,
+		})
+	}
+	for _, m := range members {
+		rv.Interfaces = append(rv.Interfaces, &l2.BridgeDomain_Interface{
+			Name:                    m.ifaceName,
+			BridgedVirtualInterface: false,
+		})
+		rv.ArpTerminationTable = append(rv.ArpTerminationTable, m.arpEntries...)
+	}
+	return rv
+}
+
+func (b *bridgeServer) bviName() string {
+	return b.name + "-bvi"
 }
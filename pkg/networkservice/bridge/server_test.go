@@ -0,0 +1,177 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridge_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	l2 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l2"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/bridge"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testvppagentcc"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func interfaceNames(bridgeDomain *l2.BridgeDomain) []string {
+	var rv []string
+	for _, iface := range bridgeDomain.GetInterfaces() {
+		rv = append(rv, iface.GetName())
+	}
+	return rv
+}
+
+func TestBridgeServer_Lifecycle(t *testing.T) {
+	cc := testvppagentcc.New()
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		bridge.NewServer(cc, "test-bridge"),
+		capture,
+	)
+
+	conn1, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	conn2, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "2"},
+	})
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetBridgeDomains(), 1)
+	bridgeDomain := capture.Config().GetVppConfig().GetBridgeDomains()[0]
+	assert.Equal(t, "test-bridge", bridgeDomain.GetName())
+	assert.Equal(t, []string{"server-1", "server-2"}, interfaceNames(bridgeDomain))
+
+	// Closing a connection while others remain shrinks the bridge domain with an Update and leaves it out of the Delete
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetBridgeDomains(), 0)
+	updates := cc.Updates()
+	require.Len(t, updates, 1)
+	require.Len(t, updates[0].GetUpdate().GetVppConfig().GetBridgeDomains(), 1)
+	assert.Equal(t, []string{"server-2"}, interfaceNames(updates[0].GetUpdate().GetVppConfig().GetBridgeDomains()[0]))
+
+	// Closing the last connection deletes the bridge domain
+	_, err = server.Close(context.Background(), conn2)
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetBridgeDomains(), 1)
+	assert.Equal(t, []string{"server-2"}, interfaceNames(capture.Config().GetVppConfig().GetBridgeDomains()[0]))
+	assert.Len(t, cc.Updates(), 1)
+}
+
+func TestBridgeServer_Settings(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		bridge.NewServer(testvppagentcc.New(), "test-bridge",
+			bridge.WithFlood(true),
+			bridge.WithUnknownUnicastFlood(true),
+			bridge.WithLearn(false),
+			bridge.WithArpTermination(true),
+			bridge.WithMacAge(5),
+			bridge.WithBVI("10.0.0.1/24"),
+		),
+		capture,
+	)
+	_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetBridgeDomains(), 1)
+	bridgeDomain := capture.Config().GetVppConfig().GetBridgeDomains()[0]
+	assert.True(t, bridgeDomain.GetFlood())
+	assert.True(t, bridgeDomain.GetUnknownUnicastFlood())
+	assert.True(t, bridgeDomain.GetForward())
+	assert.False(t, bridgeDomain.GetLearn())
+	assert.True(t, bridgeDomain.GetArpTermination())
+	assert.Equal(t, uint32(5), bridgeDomain.GetMacAge())
+	assert.Equal(t, []string{"test-bridge-bvi", "server-1"}, interfaceNames(bridgeDomain))
+	assert.True(t, bridgeDomain.GetInterfaces()[0].GetBridgedVirtualInterface())
+
+	ifaces := capture.Config().GetVppConfig().GetInterfaces()
+	require.Len(t, ifaces, 2)
+	assert.Equal(t, "server-1", ifaces[0].GetName())
+	bvi := ifaces[1]
+	assert.Equal(t, "test-bridge-bvi", bvi.GetName())
+	assert.Equal(t, []string{"10.0.0.1/24"}, bvi.GetIpAddresses())
+}
+
+func TestBridgeServer_ArpTermination(t *testing.T) {
+	cc := testvppagentcc.New()
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		bridge.NewServer(cc, "test-bridge", bridge.WithArpTermination(true)),
+		capture,
+	)
+	newConnection := func(id, srcIP, srcMac string) *networkservice.Connection {
+		return &networkservice.Connection{
+			Id: id,
+			Context: &networkservice.ConnectionContext{
+				IpContext:       &networkservice.IPContext{SrcIpAddr: srcIP},
+				EthernetContext: &networkservice.EthernetContext{SrcMac: srcMac},
+			},
+		}
+	}
+
+	conn1, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: newConnection("1", "10.0.0.2/32", "02:00:00:00:00:01"),
+	})
+	require.NoError(t, err)
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: newConnection("2", "10.0.0.3/32", "02:00:00:00:00:02"),
+	})
+	require.NoError(t, err)
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: newConnection("3", "10.0.0.4/32", ""),
+	})
+	require.NoError(t, err)
+	// Dual-stack connections get an entry for each of their addresses
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: newConnection("4", "10.0.0.5/32,fd00::5/128", "02:00:00:00:00:04"),
+	})
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetBridgeDomains(), 1)
+	assert.Equal(t, []*l2.BridgeDomain_ArpTerminationEntry{
+		{IpAddress: "10.0.0.2", PhysAddress: "02:00:00:00:00:01"},
+		{IpAddress: "10.0.0.3", PhysAddress: "02:00:00:00:00:02"},
+		{IpAddress: "10.0.0.5", PhysAddress: "02:00:00:00:00:04"},
+		{IpAddress: "fd00::5", PhysAddress: "02:00:00:00:00:04"},
+	}, capture.Config().GetVppConfig().GetBridgeDomains()[0].GetArpTerminationTable())
+
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	updates := cc.Updates()
+	require.Len(t, updates, 1)
+	require.Len(t, updates[0].GetUpdate().GetVppConfig().GetBridgeDomains(), 1)
+	assert.Equal(t, []*l2.BridgeDomain_ArpTerminationEntry{
+		{IpAddress: "10.0.0.3", PhysAddress: "02:00:00:00:00:02"},
+		{IpAddress: "10.0.0.5", PhysAddress: "02:00:00:00:00:04"},
+		{IpAddress: "fd00::5", PhysAddress: "02:00:00:00:00:04"},
+	}, updates[0].GetUpdate().GetVppConfig().GetBridgeDomains()[0].GetArpTerminationTable())
+}
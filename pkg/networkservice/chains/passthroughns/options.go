@@ -0,0 +1,96 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package passthroughns
+
+import (
+	"net"
+	"net/url"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+type serverOptions struct {
+	baseDir           string
+	tunnelIP          net.IP
+	vxlanInitFunc     func(conf *configurator.Config) error
+	clientURL         *url.URL
+	clientDialOptions []grpc.DialOption
+	serviceFunctions  []networkservice.NetworkServiceServer
+	withoutMetrics    bool
+}
+
+// Option - option for passthroughns.NewServer
+type Option func(o *serverOptions)
+
+// WithBaseDir - sets the baseDir for sockets
+func WithBaseDir(baseDir string) Option {
+	return func(o *serverOptions) {
+		o.baseDir = baseDir
+	}
+}
+
+// WithTunnelIP - sets the IP we can use for originating and terminating tunnels
+func WithTunnelIP(tunnelIP net.IP) Option {
+	return func(o *serverOptions) {
+		o.tunnelIP = tunnelIP
+	}
+}
+
+// WithVxlanInitFunc - sets the function to perform initial configuration of vppagent for vxlan
+func WithVxlanInitFunc(vxlanInitFunc func(conf *configurator.Config) error) Option {
+	return func(o *serverOptions) {
+		o.vxlanInitFunc = vxlanInitFunc
+	}
+}
+
+// WithClientURL - sets the *url.URL for the talking to the NSMgr
+func WithClientURL(clientURL *url.URL) Option {
+	return func(o *serverOptions) {
+		o.clientURL = clientURL
+	}
+}
+
+// WithClientDialOptions - sets the dialOptions for dialing the NSMgr
+func WithClientDialOptions(clientDialOptions ...grpc.DialOption) Option {
+	return func(o *serverOptions) {
+		o.clientDialOptions = clientDialOptions
+	}
+}
+
+// WithServiceFunctions - adds service functions to run the traffic through, in order.  They are run after the
+// outgoing connection has been established, when the last two vpp interfaces in the config are the incoming and the
+// outgoing vWire, for example:
+//             acl.NewServer(vppagentCC, rules) - filter traffic on the path
+// Any other element configuring vpp features on those interfaces may be used the same way.  Note: the vWires are l2
+// cross connected, so features only applying to routed traffic, like NAT44, have no effect.
+func WithServiceFunctions(serviceFunctions ...networkservice.NetworkServiceServer) Option {
+	return func(o *serverOptions) {
+		o.serviceFunctions = append(o.serviceFunctions, serviceFunctions...)
+	}
+}
+
+// WithoutMetrics - disables the collection of interface metrics
+func WithoutMetrics() Option {
+	return func(o *serverOptions) {
+		o.withoutMetrics = true
+	}
+}
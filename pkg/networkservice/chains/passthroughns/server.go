@@ -0,0 +1,125 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+// Package passthroughns provides an Endpoint that passes connections through to the next Endpoint, running their
+// traffic through a sequence of vpp features (service functions) on the way
+package passthroughns
+
+import (
+	"context"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/endpoint"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/clienturl"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/connect"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/networkservicemesh/sdk/pkg/tools/addressof"
+	"github.com/networkservicemesh/sdk/pkg/tools/token"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/commit"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/srv6"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vxlan"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/metrics"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/xconnect/l2xconnect"
+)
+
+type passthroughNSServer struct {
+	endpoint.Endpoint
+}
+
+// NewServer - returns a new vppagent based passthrough Endpoint
+// Incoming connections are connected to the next Endpoint and l2 cross connected with the outgoing connection, the
+// traffic running through the service functions (see WithServiceFunctions) on the way.
+// Note: memif connections are always plugged into vpp (never connected directly to each other), so that the traffic
+// passes through the service functions.
+//             name - name of the Endpoint
+//             authzServer - policy for allowing or rejecting requests
+//             tokenGenerator - token.GeneratorFunc for the Endpoint
+//             vppagentCC - grpc.ClientConnInterface of the vppagent
+//             opts - options, see WithBaseDir, WithTunnelIP, WithVxlanInitFunc, WithClientURL and
+//                    WithClientDialOptions for the settings of the mechanisms and of the connection to the NSMgr
+func NewServer(ctx context.Context, name string, authzServer networkservice.NetworkServiceServer, tokenGenerator token.GeneratorFunc, vppagentCC grpc.ClientConnInterface, opts ...Option) endpoint.Endpoint {
+	o := &serverOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	rv := &passthroughNSServer{}
+	rv.Endpoint = endpoint.NewServer(ctx,
+		name,
+		authzServer,
+		tokenGenerator,
+		// Make sure we have a fresh empty config for everyone in the chain to use
+		vppagent.NewServer(),
+		recvfd.NewServer(),
+		mechanisms.NewServer(map[string]networkservice.NetworkServiceServer{
+			memif.MECHANISM:  memif.NewServer(o.baseDir),
+			kernel.MECHANISM: kernel.NewServer(),
+			vxlan.MECHANISM:  vxlan.NewServer(o.tunnelIP, o.vxlanInitFunc),
+			srv6.MECHANISM:   srv6.NewServer(),
+		}),
+		// Statically set the url we use to the unix file socket for the NSMgr
+		clienturl.NewServer(o.clientURL),
+		connect.NewServer(
+			ctx,
+			client.NewClientFactory(
+				name,
+				// What to call onHeal
+				addressof.NetworkServiceClient(adapters.NewServerToClient(rv)),
+				tokenGenerator,
+				// Preference ordered list of mechanisms we support for outgoing connections
+				memif.NewClient(),
+				kernel.NewClient(),
+				vxlan.NewClient(o.tunnelIP, o.vxlanInitFunc),
+				srv6.NewClient(),
+				recvfd.NewClient()),
+			o.clientDialOptions...,
+		),
+		newPassthroughServer(o, vppagentCC),
+		sendfd.NewServer(),
+	)
+	return rv
+}
+
+// newPassthroughServer returns the part of the chain run once the outgoing connection has been established: the
+// service functions, the cross connect between the incoming and the outgoing vWire and the commit of the config
+func newPassthroughServer(o *serverOptions, vppagentCC grpc.ClientConnInterface) networkservice.NetworkServiceServer {
+	var metricsServer networkservice.NetworkServiceServer = chain.NewNetworkServiceServer()
+	if !o.withoutMetrics {
+		metricsServer = metrics.NewServer(configurator.NewStatsPollerServiceClient(vppagentCC))
+	}
+	return chain.NewNetworkServiceServer(
+		// Run the traffic through the service functions
+		chain.NewNetworkServiceServer(o.serviceFunctions...),
+		// l2 cross connect (xconnect) between incoming and outgoing connections
+		l2xconnect.NewServer(),
+		metricsServer,
+		commit.NewServer(vppagentCC),
+	)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package passthroughns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/acl"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testvppagentcc"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+func TestPassthroughServer(t *testing.T) {
+	rules, err := acl.MapToRules(map[string]string{"0": "action=permit,proto=tcp"})
+	require.NoError(t, err)
+	cc := testvppagentcc.New()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		// Incoming and outgoing vWire
+		testinterfaceappender.NewServer(),
+		adapters.NewClientToServer(testinterfaceappender.NewClient()),
+		newPassthroughServer(&serverOptions{
+			serviceFunctions: []networkservice.NetworkServiceServer{acl.NewServer(cc, rules)},
+			withoutMetrics:   true,
+		}, cc),
+	)
+
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+
+	// The service functions and the cross connect are committed together
+	require.Len(t, cc.Updates(), 1)
+	vppConfig := cc.Updates()[0].GetUpdate().GetVppConfig()
+	require.Len(t, vppConfig.GetAcls(), 1)
+	assert.Equal(t, []string{"client-1"}, vppConfig.GetAcls()[0].GetInterfaces().GetIngress())
+	require.Len(t, vppConfig.GetXconnectPairs(), 2)
+	assert.Equal(t, "server-1", vppConfig.GetXconnectPairs()[0].GetReceiveInterface())
+	assert.Equal(t, "client-1", vppConfig.GetXconnectPairs()[0].GetTransmitInterface())
+	assert.Equal(t, "client-1", vppConfig.GetXconnectPairs()[1].GetReceiveInterface())
+	assert.Equal(t, "server-1", vppConfig.GetXconnectPairs()[1].GetTransmitInterface())
+}
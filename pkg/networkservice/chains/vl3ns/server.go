@@ -0,0 +1,106 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+// Package vl3ns provides an Endpoint that implements a virtual L3 (vL3) network service
+package vl3ns
+
+import (
+	"context"
+	"net"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/client"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/chains/endpoint"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/recvfd"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
+	"github.com/networkservicemesh/sdk/pkg/tools/token"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/commit"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/srv6"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vxlan"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/metrics"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vl3"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+// NewServer - returns a new vppagent based Endpoint implementing a vL3 Network Service
+// Every incoming connection is terminated into the VRF of network, gets an address from it and a host route to it.
+// Other vL3 instances may peer with this one by connecting with NewPeerClient over a remote mechanism.
+//             name - name of the Endpoint
+//             authzServer - policy for allowing or rejecting requests
+//             tokenGenerator - token.GeneratorFunc for the Endpoint
+//             vppagentCC - grpc.ClientConnInterface of the vppagent
+//             baseDir - baseDir for sockets
+//             tunnelIP - IP we can use for terminating tunnels
+//             vxlanInitFunc - function to perform initial configuration of vppagent
+//             network - the vL3 network, shared with any peer clients of the Endpoint
+func NewServer(ctx context.Context, name string, authzServer networkservice.NetworkServiceServer, tokenGenerator token.GeneratorFunc, vppagentCC grpc.ClientConnInterface, baseDir string, tunnelIP net.IP, vxlanInitFunc func(conf *configurator.Config) error, network *vl3.Network) endpoint.Endpoint {
+	return endpoint.NewServer(ctx,
+		name,
+		authzServer,
+		tokenGenerator,
+		// Make sure we have a fresh empty config for everyone in the chain to use
+		vppagent.NewServer(),
+		recvfd.NewServer(),
+		mechanisms.NewServer(map[string]networkservice.NetworkServiceServer{
+			memif.MECHANISM:  memif.NewServer(baseDir),
+			kernel.MECHANISM: kernel.NewServer(),
+			vxlan.MECHANISM:  vxlan.NewServer(tunnelIP, vxlanInitFunc),
+			srv6.MECHANISM:   srv6.NewServer(),
+		}),
+		// Allocate addresses and plug the vpp side of the connection into the vL3 VRF
+		vl3.NewServer(network),
+		// Apply the connection context to the kernel side of kernel connections
+		connectioncontextkernel.NewServer(),
+		metrics.NewServer(configurator.NewStatsPollerServiceClient(vppagentCC)),
+		commit.NewServer(vppagentCC),
+		sendfd.NewServer(),
+	)
+}
+
+// NewPeerClient - returns a NetworkServiceClient for peering network with another vL3 instance over a remote mechanism
+//             name - name of the client
+//             tokenGenerator - token.GeneratorFunc for the client
+//             vppagentCC - grpc.ClientConnInterface of the vppagent
+//             tunnelIP - IP we can use for originating tunnels
+//             vxlanInitFunc - function to perform initial configuration of vppagent
+//             network - the vL3 network, shared with the Endpoint returned by NewServer
+//             cc - grpc.ClientConnInterface for the talking to the NSMgr
+func NewPeerClient(ctx context.Context, name string, tokenGenerator token.GeneratorFunc, vppagentCC grpc.ClientConnInterface, tunnelIP net.IP, vxlanInitFunc func(conf *configurator.Config) error, network *vl3.Network, cc grpc.ClientConnInterface) networkservice.NetworkServiceClient {
+	return client.NewClient(ctx,
+		name,
+		nil,
+		tokenGenerator,
+		cc,
+		// Make sure we have a fresh empty config for everyone in the chain to use
+		vppagent.NewClient(),
+		commit.NewClient(vppagentCC),
+		// Route the prefixes of the peer through the connection in the vL3 VRF
+		vl3.NewClient(vppagentCC, network),
+		// Preference ordered list of remote mechanisms we support for peering
+		vxlan.NewClient(tunnelIP, vxlanInitFunc),
+		srv6.NewClient(),
+	)
+}
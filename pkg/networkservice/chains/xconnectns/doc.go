@@ -15,4 +15,18 @@
 // limitations under the License.
 
 // Package xconnectns provides an Endpoint that implements the cross connect networks service for use as a Forwarder
+//
+// Migrating from the positional NewServer
+//
+// NewServer used to take the settings of the default mechanisms and of the connection to the NSMgr as positional
+// parameters:
+//             xconnectns.NewServer(ctx, name, authzServer, tokenGenerator, vppagentCC,
+//                     baseDir, tunnelIP, vxlanInitFunc, clientURL, clientDialOptions...)
+// They are options now, and any of them may be left out:
+//             xconnectns.NewServer(ctx, name, authzServer, tokenGenerator, vppagentCC,
+//                     xconnectns.WithBaseDir(baseDir),
+//                     xconnectns.WithTunnelIP(tunnelIP),
+//                     xconnectns.WithVxlanInitFunc(vxlanInitFunc),
+//                     xconnectns.WithClientURL(clientURL),
+//                     xconnectns.WithClientDialOptions(clientDialOptions...))
 package xconnectns
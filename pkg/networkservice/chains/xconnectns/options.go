@@ -0,0 +1,161 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package xconnectns
+
+import (
+	"net"
+	"net/url"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+type serverOptions struct {
+	baseDir                       string
+	tunnelIP                      net.IP
+	vxlanInitFunc                 func(conf *configurator.Config) error
+	clientURL                     *url.URL
+	clientDialOptions             []grpc.DialOption
+	mechanismOps                  []*mechanism
+	clientMechanismPreference     []string
+	additionalServerFunctionality []networkservice.NetworkServiceServer
+	additionalClientFunctionality []networkservice.NetworkServiceClient
+	withoutMetrics                bool
+	underlayMTU                   uint32
+	dnsConfigDir                  string
+}
+
+// mechanism - server and client chain elements supporting a mechanism type.  A mechanism with nil server and client
+// removes the mechanism type.
+type mechanism struct {
+	mechanismType string
+	server        networkservice.NetworkServiceServer
+	client        networkservice.NetworkServiceClient
+}
+
+// Option - option for xconnectns.NewServer
+type Option func(o *serverOptions)
+
+// WithBaseDir - sets the baseDir for sockets
+func WithBaseDir(baseDir string) Option {
+	return func(o *serverOptions) {
+		o.baseDir = baseDir
+	}
+}
+
+// WithTunnelIP - sets the IP we can use for originating and terminating tunnels
+func WithTunnelIP(tunnelIP net.IP) Option {
+	return func(o *serverOptions) {
+		o.tunnelIP = tunnelIP
+	}
+}
+
+// WithVxlanInitFunc - sets the function to perform initial configuration of vppagent for vxlan
+func WithVxlanInitFunc(vxlanInitFunc func(conf *configurator.Config) error) Option {
+	return func(o *serverOptions) {
+		o.vxlanInitFunc = vxlanInitFunc
+	}
+}
+
+// WithClientURL - sets the *url.URL for the talking to the NSMgr
+func WithClientURL(clientURL *url.URL) Option {
+	return func(o *serverOptions) {
+		o.clientURL = clientURL
+	}
+}
+
+// WithClientDialOptions - sets the dialOptions for dialing the NSMgr
+func WithClientDialOptions(clientDialOptions ...grpc.DialOption) Option {
+	return func(o *serverOptions) {
+		o.clientDialOptions = clientDialOptions
+	}
+}
+
+// WithMechanism - adds support for mechanismType, replacing the default support for it if there is one
+//             server - chain element handling mechanismType for incoming connections, nil to not accept it
+//             client - chain element requesting mechanismType for outgoing connections, nil to not request it.
+//                      Unless set otherwise with WithClientMechanismPreference, it is least preferred.
+func WithMechanism(mechanismType string, server networkservice.NetworkServiceServer, client networkservice.NetworkServiceClient) Option {
+	return func(o *serverOptions) {
+		o.mechanismOps = append(o.mechanismOps, &mechanism{
+			mechanismType: mechanismType,
+			server:        server,
+			client:        client,
+		})
+	}
+}
+
+// WithoutMechanism - removes support for mechanismType for both incoming and outgoing connections
+func WithoutMechanism(mechanismType string) Option {
+	return func(o *serverOptions) {
+		o.mechanismOps = append(o.mechanismOps, &mechanism{
+			mechanismType: mechanismType,
+		})
+	}
+}
+
+// WithClientMechanismPreference - sets the preference order of mechanisms for outgoing connections.  The listed
+// mechanism types are preferred in the given order, any others follow in their default order.
+func WithClientMechanismPreference(mechanismTypes ...string) Option {
+	return func(o *serverOptions) {
+		o.clientMechanismPreference = mechanismTypes
+	}
+}
+
+// WithAdditionalServerFunctionality - adds chain elements run after the outgoing connection has been established and
+// before the incoming and outgoing vWires are cross connected.  At that point the last two vpp interfaces in the
+// config are the incoming and the outgoing vWire, so this is the place for ACLs, policers and the like.
+func WithAdditionalServerFunctionality(servers ...networkservice.NetworkServiceServer) Option {
+	return func(o *serverOptions) {
+		o.additionalServerFunctionality = append(o.additionalServerFunctionality, servers...)
+	}
+}
+
+// WithAdditionalClientFunctionality - adds chain elements to the client for outgoing connections.  They are run
+// before the mechanism chain elements on Request, and so see the outgoing vWire once the Request returns.
+func WithAdditionalClientFunctionality(clients ...networkservice.NetworkServiceClient) Option {
+	return func(o *serverOptions) {
+		o.additionalClientFunctionality = append(o.additionalClientFunctionality, clients...)
+	}
+}
+
+// WithoutMetrics - disables the collection of interface metrics
+func WithoutMetrics() Option {
+	return func(o *serverOptions) {
+		o.withoutMetrics = true
+	}
+}
+
+// WithMTU - sets the MTU of the network carrying remote mechanisms.  When set, the MTU of each cross connect is
+// computed from it and the encapsulation overhead of its mechanisms, and applied to its interfaces.
+func WithMTU(underlayMTU uint32) Option {
+	return func(o *serverOptions) {
+		o.underlayMTU = underlayMTU
+	}
+}
+
+// WithDNSConfigDir - enables writing the DnsContext of kernel connections as resolv.conf files under dnsConfigDir, see
+// connectioncontextkernel.WithDNSConfigDir
+func WithDNSConfigDir(dnsConfigDir string) Option {
+	return func(o *serverOptions) {
+		o.dnsConfigDir = dnsConfigDir
+	}
+}
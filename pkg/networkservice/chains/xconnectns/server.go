@@ -21,11 +21,10 @@ package xconnectns
 
 import (
 	"context"
-	"net"
-	"net/url"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/common/mechanisms/sendfd"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
 	"github.com/networkservicemesh/sdk/pkg/tools/addressof"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
@@ -52,6 +51,7 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/srv6"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vxlan"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mtu"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/xconnect/l2xconnect"
 )
@@ -63,13 +63,33 @@ type xconnectNSServer struct {
 // NewServer - returns a new vppagent based Endpoint implementing the XConnect Network Service for use as a Forwarder
 //             name - name of the Forwarder
 //             authzPolicy - policy for allowing or rejecting requests
+//             tokenGenerator - token.GeneratorFunc for the Forwarder
 //             vppagentCC - grpc.ClientConnInterface of the vppagent
-//             baseDir - baseDir for sockets
-//             tunnelIP - IP we can use for originating and terminating tunnels
-//             vxlanInitFunc - function to perform initial configuration of vppagent
-//             clientUrl - *url.URL for the talking to the NSMgr
-//             ...clientDialOptions - dialOptions for dialing the NSMgr
-func NewServer(ctx context.Context, name string, authzServer networkservice.NetworkServiceServer, tokenGenerator token.GeneratorFunc, vppagentCC grpc.ClientConnInterface, baseDir string, tunnelIP net.IP, vxlanInitFunc func(conf *configurator.Config) error, clientURL *url.URL, clientDialOptions ...grpc.DialOption) endpoint.Endpoint {
+//             opts - options, see WithBaseDir, WithTunnelIP, WithVxlanInitFunc, WithClientURL and
+//                    WithClientDialOptions for the settings of the default mechanisms and of the connection to the NSMgr
+func NewServer(ctx context.Context, name string, authzServer networkservice.NetworkServiceServer, tokenGenerator token.GeneratorFunc, vppagentCC grpc.ClientConnInterface, opts ...Option) endpoint.Endpoint {
+	o := &serverOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	serverMechanisms, clientMechanisms := o.mechanisms()
+
+	var metricsServer networkservice.NetworkServiceServer = chain.NewNetworkServiceServer()
+	if !o.withoutMetrics {
+		metricsServer = metrics.NewServer(configurator.NewStatsPollerServiceClient(vppagentCC))
+	}
+
+	var mtuServer networkservice.NetworkServiceServer = chain.NewNetworkServiceServer()
+	clientFunctionality := []networkservice.NetworkServiceClient{connectioncontextkernel.NewClient()}
+	if o.underlayMTU != 0 {
+		mtuServer = mtu.NewServer(o.underlayMTU)
+		clientFunctionality = append(clientFunctionality, mtu.NewClient(o.underlayMTU))
+	}
+	clientFunctionality = append(clientFunctionality, o.additionalClientFunctionality...)
+	// Preference ordered list of mechanisms we support for outgoing connections
+	clientFunctionality = append(clientFunctionality, clientMechanisms...)
+	clientFunctionality = append(clientFunctionality, recvfd.NewClient())
+
 	rv := &xconnectNSServer{}
 	rv.Endpoint = endpoint.NewServer(ctx,
 		name,
@@ -78,14 +98,10 @@ func NewServer(ctx context.Context, name string, authzServer networkservice.Netw
 		// Make sure we have a fresh empty config for everyone in the chain to use
 		vppagent.NewServer(),
 		recvfd.NewServer(),
-		mechanisms.NewServer(map[string]networkservice.NetworkServiceServer{
-			memif.MECHANISM:  memif.NewServer(baseDir),
-			kernel.MECHANISM: kernel.NewServer(),
-			vxlan.MECHANISM:  vxlan.NewServer(tunnelIP, vxlanInitFunc),
-			srv6.MECHANISM:   srv6.NewServer(),
-		}),
+		mechanisms.NewServer(serverMechanisms),
+		mtuServer,
 		// Statically set the url we use to the unix file socket for the NSMgr
-		clienturl.NewServer(clientURL),
+		clienturl.NewServer(o.clientURL),
 		connect.NewServer(
 			ctx,
 			client.NewClientFactory(
@@ -93,23 +109,68 @@ func NewServer(ctx context.Context, name string, authzServer networkservice.Netw
 				// What to call onHeal
 				addressof.NetworkServiceClient(adapters.NewServerToClient(rv)),
 				tokenGenerator,
-				connectioncontextkernel.NewClient(),
-				// Preference ordered list of mechanisms we support for outgoing connections
-				memif.NewClient(),
-				kernel.NewClient(),
-				vxlan.NewClient(tunnelIP, vxlanInitFunc),
-				srv6.NewClient(),
-				recvfd.NewClient()),
-			clientDialOptions...,
+				clientFunctionality...),
+			o.clientDialOptions...,
 		),
 		directmemif.NewServer(),
-		connectioncontextkernel.NewServer(),
+		connectioncontextkernel.NewServer(connectioncontextkernel.WithDNSConfigDir(o.dnsConfigDir)),
+		chain.NewNetworkServiceServer(o.additionalServerFunctionality...),
 		// TODO - properly support l3xconnect for IP payload
 		// l2 cross connect (xconnect) between incoming and outgoing connections
 		l2xconnect.NewServer(),
-		metrics.NewServer(configurator.NewStatsPollerServiceClient(vppagentCC)),
+		metricsServer,
 		commit.NewServer(vppagentCC),
 		sendfd.NewServer(),
 	)
 	return rv
 }
+
+// mechanisms returns the mechanism map for incoming connections and the preference ordered list of mechanisms for
+// outgoing connections
+func (o *serverOptions) mechanisms() (map[string]networkservice.NetworkServiceServer, []networkservice.NetworkServiceClient) {
+	serverMechanisms := make(map[string]networkservice.NetworkServiceServer)
+	var clientMechanisms []networkservice.NetworkServiceClient
+	for _, m := range o.supportedMechanisms() {
+		if m.server != nil {
+			serverMechanisms[m.mechanismType] = m.server
+		}
+		if m.client != nil {
+			clientMechanisms = append(clientMechanisms, m.client)
+		}
+	}
+	return serverMechanisms, clientMechanisms
+}
+
+// supportedMechanisms returns the default mechanisms merged with those set by options, in order of preference for
+// outgoing connections
+func (o *serverOptions) supportedMechanisms() []*mechanism {
+	supported := []*mechanism{
+		{mechanismType: memif.MECHANISM, server: memif.NewServer(o.baseDir), client: memif.NewClient()},
+		{mechanismType: kernel.MECHANISM, server: kernel.NewServer(), client: kernel.NewClient()},
+		{mechanismType: vxlan.MECHANISM, server: vxlan.NewServer(o.tunnelIP, o.vxlanInitFunc), client: vxlan.NewClient(o.tunnelIP, o.vxlanInitFunc)},
+		{mechanismType: srv6.MECHANISM, server: srv6.NewServer(), client: srv6.NewClient()},
+	}
+	for _, op := range o.mechanismOps {
+		for i, m := range supported {
+			if m.mechanismType == op.mechanismType {
+				supported = append(supported[:i], supported[i+1:]...)
+				break
+			}
+		}
+		if op.server != nil || op.client != nil {
+			supported = append(supported, op)
+		}
+	}
+
+	var preferred []*mechanism
+	for _, mechanismType := range o.clientMechanismPreference {
+		for i, m := range supported {
+			if m.mechanismType == mechanismType {
+				preferred = append(preferred, m)
+				supported = append(supported[:i], supported[i+1:]...)
+				break
+			}
+		}
+	}
+	return append(preferred, supported...)
+}
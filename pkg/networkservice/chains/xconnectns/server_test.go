@@ -0,0 +1,231 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package xconnectns
+
+import (
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/utils/inject/injecterror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/srv6"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/vxlan"
+)
+
+const customMechanism = "CUSTOM"
+
+func TestServerOptions_Mechanisms(t *testing.T) {
+	customServer, customClient := injecterror.NewServer(), injecterror.NewClient()
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		// clientTypes - mechanism types of the clients for outgoing connections, in order of preference
+		clientTypes []string
+		// serverTypes - mechanism types accepted for incoming connections
+		serverTypes []string
+	}{
+		{
+			name:        "default",
+			clientTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM},
+			serverTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM},
+		},
+		{
+			name:        "added mechanism is least preferred",
+			opts:        []Option{WithMechanism(customMechanism, customServer, customClient)},
+			clientTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM, customMechanism},
+			serverTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM, customMechanism},
+		},
+		{
+			name:        "replaced mechanism is least preferred",
+			opts:        []Option{WithMechanism(kernel.MECHANISM, customServer, customClient)},
+			clientTypes: []string{memif.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM, kernel.MECHANISM},
+			serverTypes: []string{memif.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM, kernel.MECHANISM},
+		},
+		{
+			name:        "mechanism without client is only accepted",
+			opts:        []Option{WithMechanism(srv6.MECHANISM, customServer, nil)},
+			clientTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM},
+			serverTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM},
+		},
+		{
+			name:        "mechanism without server is only requested",
+			opts:        []Option{WithMechanism(srv6.MECHANISM, nil, customClient)},
+			clientTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM},
+			serverTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM},
+		},
+		{
+			name:        "removed mechanism",
+			opts:        []Option{WithoutMechanism(vxlan.MECHANISM)},
+			clientTypes: []string{memif.MECHANISM, kernel.MECHANISM, srv6.MECHANISM},
+			serverTypes: []string{memif.MECHANISM, kernel.MECHANISM, srv6.MECHANISM},
+		},
+		{
+			name:        "removed and added again",
+			opts:        []Option{WithoutMechanism(memif.MECHANISM), WithMechanism(memif.MECHANISM, customServer, customClient)},
+			clientTypes: []string{kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM, memif.MECHANISM},
+			serverTypes: []string{kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM, memif.MECHANISM},
+		},
+		{
+			name:        "preference",
+			opts:        []Option{WithClientMechanismPreference(vxlan.MECHANISM, kernel.MECHANISM)},
+			clientTypes: []string{vxlan.MECHANISM, kernel.MECHANISM, memif.MECHANISM, srv6.MECHANISM},
+			serverTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM},
+		},
+		{
+			name: "preference of added and unknown mechanisms",
+			opts: []Option{
+				WithClientMechanismPreference("UNKNOWN", customMechanism, srv6.MECHANISM),
+				WithMechanism(customMechanism, customServer, customClient),
+			},
+			clientTypes: []string{customMechanism, srv6.MECHANISM, memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM},
+			serverTypes: []string{memif.MECHANISM, kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM, customMechanism},
+		},
+		{
+			name: "preference of removed mechanism",
+			opts: []Option{
+				WithClientMechanismPreference(memif.MECHANISM, kernel.MECHANISM),
+				WithoutMechanism(memif.MECHANISM),
+			},
+			clientTypes: []string{kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM},
+			serverTypes: []string{kernel.MECHANISM, vxlan.MECHANISM, srv6.MECHANISM},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			o := &serverOptions{}
+			for _, opt := range tc.opts {
+				opt(o)
+			}
+			var clientTypes []string
+			for _, m := range o.supportedMechanisms() {
+				if m.client != nil {
+					clientTypes = append(clientTypes, m.mechanismType)
+				}
+			}
+			assert.Equal(t, tc.clientTypes, clientTypes)
+
+			serverMechanisms, clientMechanisms := o.mechanisms()
+			assert.Len(t, clientMechanisms, len(tc.clientTypes))
+			require.Len(t, serverMechanisms, len(tc.serverTypes))
+			for _, mechanismType := range tc.serverTypes {
+				assert.NotNil(t, serverMechanisms[mechanismType], mechanismType)
+			}
+		})
+	}
+}
+
+func TestServerOptions_Mechanisms_Replaced(t *testing.T) {
+	customServer, customClient := injecterror.NewServer(), injecterror.NewClient()
+	o := &serverOptions{}
+	WithMechanism(kernel.MECHANISM, customServer, customClient)(o)
+	WithClientMechanismPreference(kernel.MECHANISM)(o)
+
+	serverMechanisms, clientMechanisms := o.mechanisms()
+	assert.Same(t, customServer, serverMechanisms[kernel.MECHANISM])
+	require.Len(t, clientMechanisms, 4)
+	assert.Same(t, customClient, clientMechanisms[0])
+}
+
+func TestServerOptions(t *testing.T) {
+	clientURL := &url.URL{Scheme: "unix", Path: "/var/lib/networkservicemesh/nsm.io.sock"}
+	server := injecterror.NewServer()
+	client := injecterror.NewClient()
+	for _, tc := range []struct {
+		name     string
+		opt      Option
+		expected *serverOptions
+	}{
+		{
+			name:     "WithBaseDir",
+			opt:      WithBaseDir("/tmp/memif"),
+			expected: &serverOptions{baseDir: "/tmp/memif"},
+		},
+		{
+			name:     "WithTunnelIP",
+			opt:      WithTunnelIP(net.ParseIP("10.0.0.1")),
+			expected: &serverOptions{tunnelIP: net.ParseIP("10.0.0.1")},
+		},
+		{
+			name:     "WithClientURL",
+			opt:      WithClientURL(clientURL),
+			expected: &serverOptions{clientURL: clientURL},
+		},
+		{
+			name:     "WithClientMechanismPreference",
+			opt:      WithClientMechanismPreference(vxlan.MECHANISM, kernel.MECHANISM),
+			expected: &serverOptions{clientMechanismPreference: []string{vxlan.MECHANISM, kernel.MECHANISM}},
+		},
+		{
+			name:     "WithAdditionalServerFunctionality",
+			opt:      WithAdditionalServerFunctionality(server),
+			expected: &serverOptions{additionalServerFunctionality: []networkservice.NetworkServiceServer{server}},
+		},
+		{
+			name:     "WithAdditionalClientFunctionality",
+			opt:      WithAdditionalClientFunctionality(client),
+			expected: &serverOptions{additionalClientFunctionality: []networkservice.NetworkServiceClient{client}},
+		},
+		{
+			name:     "WithoutMetrics",
+			opt:      WithoutMetrics(),
+			expected: &serverOptions{withoutMetrics: true},
+		},
+		{
+			name:     "WithMTU",
+			opt:      WithMTU(9000),
+			expected: &serverOptions{underlayMTU: 9000},
+		},
+		{
+			name:     "WithDNSConfigDir",
+			opt:      WithDNSConfigDir("/var/lib/networkservicemesh/dns"),
+			expected: &serverOptions{dnsConfigDir: "/var/lib/networkservicemesh/dns"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			o := &serverOptions{}
+			tc.opt(o)
+			assert.Equal(t, tc.expected, o)
+		})
+	}
+
+	// Functions can not be compared, so WithVxlanInitFunc is checked by calling the function set, and
+	// WithClientDialOptions, whose options are functions too, by their number
+	called := false
+	o := &serverOptions{}
+	WithVxlanInitFunc(func(conf *configurator.Config) error {
+		called = true
+		return nil
+	})(o)
+	require.NotNil(t, o.vxlanInitFunc)
+	require.NoError(t, o.vxlanInitFunc(nil))
+	assert.True(t, called)
+
+	WithClientDialOptions(grpc.WithInsecure(), grpc.WithBlock())(o)
+	assert.Len(t, o.clientDialOptions, 2)
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type setVppArpsClient struct{}
+
+// NewClient creates a NetworkServiceClient chain element to set static neighbor entries on a vpp interface
+// The vpp interface of the Client has the src addresses, so an entry pointing at the DstMac is added for every dst
+// address of the connection.
+func NewClient() networkservice.NetworkServiceClient {
+	return &setVppArpsClient{}
+}
+
+func (s *setVppArpsClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	appendArpEntries(vppagent.Config(ctx).GetVppConfig(), conn.GetContext().GetIpContext().GetDstIpAddr(), conn.GetContext().GetEthernetContext().GetDstMac())
+	return conn, nil
+}
+
+func (s *setVppArpsClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	appendArpEntries(vppagent.Config(ctx).GetVppConfig(), conn.GetContext().GetIpContext().GetDstIpAddr(), conn.GetContext().GetEthernetContext().GetDstMac())
+	return rv, nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package arps provides networkservice chain elements for setting static ARP (IPv4) and neighbor discovery (IPv6)
+// entries on the vpp side of vWires being plugged into vppagent
+package arps
+
+import (
+	"net"
+	"strings"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+)
+
+// appendArpEntries adds a static entry pointing at physAddress to the last vpp interface for each of the addresses in
+// the comma separated list ipAddrs
+func appendArpEntries(vppConfig *vpp.ConfigData, ipAddrs, physAddress string) {
+	index := len(vppConfig.GetInterfaces()) - 1
+	if index < 0 || physAddress == "" {
+		return
+	}
+	for _, ipAddr := range ipaddrs.Split(ipAddrs) {
+		ip := net.ParseIP(strings.Split(ipAddr, "/")[0])
+		if ip == nil {
+			continue
+		}
+		vppConfig.Arps = append(vppConfig.Arps, &vpp.ARPEntry{
+			Interface:   vppConfig.GetInterfaces()[index].GetName(),
+			IpAddress:   ip.String(),
+			PhysAddress: physAddress,
+			Static:      true,
+		})
+	}
+}
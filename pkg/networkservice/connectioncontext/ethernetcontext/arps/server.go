@@ -0,0 +1,47 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type setVppArpsServer struct{}
+
+// NewServer creates a NetworkServiceServer chain element to set static neighbor entries on a vpp interface
+// The vpp interface plugged into the Endpoint has the dst addresses, so an entry pointing at the SrcMac is added for
+// every src address of the connection.
+func NewServer() networkservice.NetworkServiceServer {
+	return &setVppArpsServer{}
+}
+
+func (s *setVppArpsServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conn := request.GetConnection()
+	appendArpEntries(vppagent.Config(ctx).GetVppConfig(), conn.GetContext().GetIpContext().GetSrcIpAddr(), conn.GetContext().GetEthernetContext().GetSrcMac())
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *setVppArpsServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	appendArpEntries(vppagent.Config(ctx).GetVppConfig(), conn.GetContext().GetIpContext().GetSrcIpAddr(), conn.GetContext().GetEthernetContext().GetSrcMac())
+	return next.Server(ctx).Close(ctx, conn)
+}
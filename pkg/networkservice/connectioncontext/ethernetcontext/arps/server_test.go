@@ -0,0 +1,85 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/chain"
+	"github.com/stretchr/testify/assert"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const (
+	srcMac = "0a:1b:3c:4d:5e:6f"
+	dstMac = "0a:1b:3c:4d:5e:70"
+)
+
+func connection() *networkservice.Connection {
+	return &networkservice.Connection{
+		Id: "1",
+		Context: &networkservice.ConnectionContext{
+			EthernetContext: &networkservice.EthernetContext{
+				SrcMac: srcMac,
+				DstMac: dstMac,
+			},
+			IpContext: &networkservice.IPContext{
+				SrcIpAddr: "10.0.0.1/32,fd00::1/128",
+				DstIpAddr: "10.0.0.2/32,fd00::2/128",
+			},
+		},
+	}
+}
+
+func TestSetVppArpsServer(t *testing.T) {
+	server := chain.NewNetworkServiceServer(
+		testinterfaceappender.NewServer(),
+		arps.NewServer(),
+	)
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, &networkservice.NetworkServiceRequest{Connection: connection()})
+	assert.Nil(t, err)
+	assert.Equal(t, []*vpp.ARPEntry{
+		{Interface: "server-1", IpAddress: "10.0.0.1", PhysAddress: srcMac, Static: true},
+		{Interface: "server-1", IpAddress: "fd00::1", PhysAddress: srcMac, Static: true},
+	}, vppagent.Config(ctx).GetVppConfig().GetArps())
+
+	ctx = vppagent.WithConfig(context.Background())
+	_, err = server.Close(ctx, connection())
+	assert.Nil(t, err)
+	assert.Len(t, vppagent.Config(ctx).GetVppConfig().GetArps(), 2)
+}
+
+func TestSetVppArpsClient(t *testing.T) {
+	client := chain.NewNetworkServiceClient(
+		arps.NewClient(),
+		testinterfaceappender.NewClient(),
+	)
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := client.Request(ctx, &networkservice.NetworkServiceRequest{Connection: connection()})
+	assert.Nil(t, err)
+	assert.Equal(t, []*vpp.ARPEntry{
+		{Interface: "client-1", IpAddress: "10.0.0.2", PhysAddress: dstMac, Static: true},
+		{Interface: "client-1", IpAddress: "fd00::2", PhysAddress: dstMac, Static: true},
+	}, vppagent.Config(ctx).GetVppConfig().GetArps())
+}
@@ -26,6 +26,8 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 )
@@ -34,7 +36,9 @@ type setVppIPClient struct{}
 
 // NewClient creates a NetworkServiceClient chain element to set the ip address on a vpp interface
 // It sets the IP Address on the *vpp* side of an interface leaving the
-// Endpoint.
+// Endpoint.  The src address may be a comma separated list of addresses (see ipaddrs), all of which are added to
+// the addresses already set on the interface.  The addresses are allocated in the netalloc config and the interface
+// refers to the allocations (see ipalloc).
 //                                         Endpoint
 //                              +---------------------------+
 //                              |                           |
@@ -63,18 +67,23 @@ func (s *setVppIPClient) Request(ctx context.Context, request *networkservice.Ne
 	if err != nil {
 		return nil, err
 	}
-	conf := vppagent.Config(ctx)
-	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 && conn.GetContext().GetIpContext().GetSrcIpAddr() != "" {
-		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{conn.GetContext().GetIpContext().GetSrcIpAddr()}
-	}
+	s.appendIPAddresses(ctx, conn)
 	return conn, nil
 }
 
 func (s *setVppIPClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
 	e, err := next.Client(ctx).Close(ctx, conn, opts...)
+	s.appendIPAddresses(ctx, conn)
+	return e, err
+}
+
+func (s *setVppIPClient) appendIPAddresses(ctx context.Context, conn *networkservice.Connection) {
 	conf := vppagent.Config(ctx)
-	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 && conn.GetContext().GetIpContext().GetSrcIpAddr() != "" {
-		conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{conn.GetContext().GetIpContext().GetSrcIpAddr()}
+	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
+		iface := conf.GetVppConfig().GetInterfaces()[index]
+		allocations := ipalloc.Allocations(conn.GetId(), iface.GetName(),
+			ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr()),
+			ipaddrs.Split(conn.GetContext().GetIpContext().GetDstIpAddr()))
+		iface.IpAddresses = ipaddrs.Append(iface.GetIpAddresses(), ipalloc.Append(conf.GetNetallocConfig(), allocations...)...)
 	}
-	return e, err
 }
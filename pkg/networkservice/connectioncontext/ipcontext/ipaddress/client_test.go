@@ -37,6 +37,7 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
 )
 
 const (
@@ -71,7 +72,9 @@ func TestSetIPVppClient(t *testing.T) {
 			conf := vppagent.Config(ctx)
 			numInterfaces := len(conf.GetVppConfig().GetInterfaces())
 			require.Greater(t, numInterfaces, 0)
-			assert.Equal(t, IPAddress, conf.GetVppConfig().GetInterfaces()[numInterfaces-1].GetIpAddresses()[0])
+			iface := conf.GetVppConfig().GetInterfaces()[numInterfaces-1]
+			assert.Equal(t, ipalloc.Ref("-ipv4-0", iface.GetName()), iface.GetIpAddresses()[0])
+			assert.Equal(t, IPAddress+"/32", conf.GetNetallocConfig().GetIpAddresses()[0].GetAddress())
 		}),
 		ipaddress.NewClient(),
 		checkcontext.NewClient(t, func(t *testing.T, ctx context.Context) {
@@ -25,13 +25,17 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
 )
 
 type setVppIPServer struct{}
 
 // NewServer creates a NetworkServiceServer chain element to set the ip address on a vpp interface
 // It sets the IP Address on the *vpp* side of an interface plugged into the
-// Endpoint.
+// Endpoint.  The dst address may be a comma separated list of addresses (see ipaddrs), all of which are added to
+// the addresses already set on the interface.  The addresses are allocated in the netalloc config and the interface
+// refers to the allocations (see ipalloc).
 //                                         Endpoint
 //                              +---------------------------+
 //                              |                           |
@@ -56,23 +60,22 @@ func NewServer() networkservice.NetworkServiceServer {
 }
 
 func (s *setVppIPServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	conf := vppagent.Config(ctx)
-	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
-		dstIP := request.GetConnection().GetContext().GetIpContext().GetDstIpAddr()
-		if dstIP != "" {
-			conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{dstIP}
-		}
-	}
+	s.appendIPAddresses(ctx, request.GetConnection())
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (s *setVppIPServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	s.appendIPAddresses(ctx, conn)
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func (s *setVppIPServer) appendIPAddresses(ctx context.Context, conn *networkservice.Connection) {
 	conf := vppagent.Config(ctx)
 	if index := len(conf.GetVppConfig().GetInterfaces()) - 1; index >= 0 {
-		dstIP := conn.GetContext().GetIpContext().GetDstIpAddr()
-		if dstIP != "" {
-			conf.GetVppConfig().GetInterfaces()[index].IpAddresses = []string{dstIP}
-		}
+		iface := conf.GetVppConfig().GetInterfaces()[index]
+		allocations := ipalloc.Allocations(conn.GetId(), iface.GetName(),
+			ipaddrs.Split(conn.GetContext().GetIpContext().GetDstIpAddr()),
+			ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr()))
+		iface.IpAddresses = ipaddrs.Append(iface.GetIpAddresses(), ipalloc.Append(conf.GetNetallocConfig(), allocations...)...)
 	}
-	return next.Server(ctx).Close(ctx, conn)
 }
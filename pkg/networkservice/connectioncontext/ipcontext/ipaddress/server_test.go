@@ -33,6 +33,7 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/memif"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
 )
 
 func serverRequest() *networkservice.NetworkServiceRequest {
@@ -69,9 +70,10 @@ func TestSetIPVppServer_Request(t *testing.T) {
 	conf := vppagent.Config(ctx)
 	numInterfaces := len(conf.GetVppConfig().GetInterfaces())
 	require.Greater(t, numInterfaces, 0)
-	ipAddresses := conf.GetVppConfig().GetInterfaces()[numInterfaces-1].GetIpAddresses()
-	require.Greater(t, len(ipAddresses), 0)
-	assert.Equal(t, IPAddress, ipAddresses[0])
+	iface := conf.GetVppConfig().GetInterfaces()[numInterfaces-1]
+	require.Greater(t, len(iface.GetIpAddresses()), 0)
+	assert.Equal(t, ipalloc.Ref("-ipv4-0", iface.GetName()), iface.GetIpAddresses()[0])
+	assert.Equal(t, IPAddress+"/32", conf.GetNetallocConfig().GetIpAddresses()[0].GetAddress())
 }
 
 func TestSetIPVppServer_Close(t *testing.T) {
@@ -88,9 +90,10 @@ func TestSetIPVppServer_Close(t *testing.T) {
 	conf := vppagent.Config(ctx)
 	numInterfaces := len(conf.GetVppConfig().GetInterfaces())
 	require.Greater(t, numInterfaces, 0)
-	ipAddresses := conf.GetVppConfig().GetInterfaces()[numInterfaces-1].GetIpAddresses()
-	require.Greater(t, len(ipAddresses), 0)
-	assert.Equal(t, IPAddress, ipAddresses[0])
+	iface := conf.GetVppConfig().GetInterfaces()[numInterfaces-1]
+	require.Greater(t, len(iface.GetIpAddresses()), 0)
+	assert.Equal(t, ipalloc.Ref("-ipv4-0", iface.GetName()), iface.GetIpAddresses()[0])
+	assert.Equal(t, IPAddress+"/32", conf.GetNetallocConfig().GetIpAddresses()[0].GetAddress())
 }
 
 func TestSetIPVppServerPropagatesError(t *testing.T) {
@@ -104,3 +107,25 @@ func TestSetIPVppServerPropagatesError(t *testing.T) {
 	_, err = server.Close(vppagent.WithConfig(context.Background()), serverRequest().GetConnection())
 	assert.NotNil(t, err)
 }
+
+func TestSetIPVppServer_DualStack(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		memif.NewServer(BaseDir),
+		ipaddress.NewServer(),
+	)
+	request := serverRequest()
+	request.GetConnection().GetContext().GetIpContext().DstIpAddr = "10.0.0.1/32,fd00::1/128"
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, request)
+	require.NoError(t, err)
+
+	conf := vppagent.Config(ctx)
+	numInterfaces := len(conf.GetVppConfig().GetInterfaces())
+	require.Greater(t, numInterfaces, 0)
+	iface := conf.GetVppConfig().GetInterfaces()[numInterfaces-1]
+	assert.Equal(t, []string{ipalloc.Ref("-ipv4-0", iface.GetName()), ipalloc.Ref("-ipv6-0", iface.GetName())}, iface.GetIpAddresses())
+	require.Len(t, conf.GetNetallocConfig().GetIpAddresses(), 2)
+	assert.Equal(t, "10.0.0.1/32", conf.GetNetallocConfig().GetIpAddresses()[0].GetAddress())
+	assert.Equal(t, "fd00::1/128", conf.GetNetallocConfig().GetIpAddresses()[1].GetAddress())
+}
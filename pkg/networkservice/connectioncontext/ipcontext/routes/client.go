@@ -28,6 +28,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
 )
 
 type setVppRoutesClient struct{}
@@ -84,16 +85,6 @@ func (s *setVppRoutesClient) addRoutes(ctx context.Context, conn *networkservice
 	if iface == nil {
 		return
 	}
-	// Extract the dstIP and DstNet
-	dstIP, dstNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetDstIpAddr())
-	if err != nil {
-		return
-	}
-	_, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
-	if err != nil {
-		return
-	}
-
 	// Loop over any explicit routes returned with the ConnectionContext and add them
 	duplicatedPrefixes := make(map[string]bool)
 	for _, route := range conn.GetContext().GetIpContext().GetSrcRoutes() {
@@ -105,17 +96,29 @@ func (s *setVppRoutesClient) addRoutes(ctx context.Context, conn *networkservice
 			})
 		}
 	}
-	// If srcNet contains dstIP then dstIP is reachable and we are done
-	if _, ok := duplicatedPrefixes[dstNet.String()]; ok || srcNet.Contains(dstIP) {
-		return
-	}
+	srcIPAddrs := ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr())
+	for _, dstIPAddr := range ipaddrs.Split(conn.GetContext().GetIpContext().GetDstIpAddr()) {
+		// Extract the dstIP and DstNet
+		dstIP, dstNet, err := net.ParseCIDR(dstIPAddr)
+		if err != nil {
+			continue
+		}
+		srcNet := ipaddrs.OfFamily(srcIPAddrs, dstIP)
+		if srcNet == nil {
+			continue
+		}
+		// If srcNet contains dstIP then dstIP is reachable and we are done
+		if _, ok := duplicatedPrefixes[dstNet.String()]; ok || srcNet.Contains(dstIP) {
+			continue
+		}
 
-	// Otherwise add a route to dstNet. using dstIP a nextHop
-	if dstIP.IsGlobalUnicast() {
-		vppagent.Config(ctx).GetVppConfig().Routes = append(vppagent.Config(ctx).GetVppConfig().Routes, &vpp.Route{
-			DstNetwork:        dstNet.String(),
-			OutgoingInterface: iface.GetName(),
-			VrfId:             iface.Vrf,
-		})
+		// Otherwise add a route to dstNet. using dstIP a nextHop
+		if dstIP.IsGlobalUnicast() {
+			vppagent.Config(ctx).GetVppConfig().Routes = append(vppagent.Config(ctx).GetVppConfig().Routes, &vpp.Route{
+				DstNetwork:        dstNet.String(),
+				OutgoingInterface: iface.GetName(),
+				VrfId:             iface.Vrf,
+			})
+		}
 	}
 }
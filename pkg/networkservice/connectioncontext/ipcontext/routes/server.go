@@ -27,6 +27,7 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
 )
 
 type setVppRoutesServer struct{}
@@ -68,17 +69,17 @@ func (s *setVppRoutesServer) Close(ctx context.Context, conn *networkservice.Con
 }
 
 func (s *setVppRoutesServer) addRoutes(ctx context.Context, conn *networkservice.Connection) {
-	if conn.GetContext().GetIpContext().GetSrcIpAddr() == "" {
-		return
-	}
-	srcIP, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
-	if err != nil {
-		return
-	}
 	conf := vppagent.Config(ctx)
 	index := len(conf.GetVppConfig().GetInterfaces()) - 1
-	if index >= 0 && srcIP.IsGlobalUnicast() {
-		iface := conf.GetVppConfig().GetInterfaces()[index]
+	if index < 0 {
+		return
+	}
+	iface := conf.GetVppConfig().GetInterfaces()[index]
+	for _, srcIPAddr := range ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr()) {
+		srcIP, srcNet, err := net.ParseCIDR(srcIPAddr)
+		if err != nil || !srcIP.IsGlobalUnicast() {
+			continue
+		}
 		vppagent.Config(ctx).GetVppConfig().Routes = append(vppagent.Config(ctx).GetVppConfig().Routes, &vpp.Route{
 			DstNetwork:        srcNet.String(),
 			OutgoingInterface: iface.GetName(),
@@ -25,6 +25,7 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/arps"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ethernetcontext/macaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/ipaddress"
 )
@@ -55,6 +56,7 @@ func NewServer() networkservice.NetworkServiceServer {
 	return chain.NewNetworkServiceServer(
 		ipaddress.NewServer(),
 		macaddress.NewServer(),
+		arps.NewServer(),
 		routes.NewServer(),
 	)
 }
@@ -23,6 +23,7 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ethernetcontext/arps"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
 )
@@ -53,6 +54,7 @@ import (
 func NewClient() networkservice.NetworkServiceClient {
 	return chain.NewNetworkServiceClient(
 		routes.NewClient(),
+		arps.NewClient(),
 		ipaddress.NewClient(),
 	)
 }
@@ -0,0 +1,239 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+// Package dnscontext provides a NetworkServiceServer that writes the resolver configuration from the connection
+// context for the network namespaces of kernel interfaces
+package dnscontext
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netns"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+const (
+	// ResolvConfFilename - name of the resolver configuration file written for each network namespace
+	ResolvConfFilename = "resolv.conf"
+	resolvConfMode     = 0644
+	dirMode            = 0755
+)
+
+type dnsContextServer struct {
+	configDir string
+	// configs - dns configs of the connections with a kernel interface, keyed by network namespace inode and then
+	// by connection id
+	configs map[uint64]map[string][]*networkservice.DNSConfig
+	// inodes - network namespace inodes of the connections in configs, keyed by connection id, so that Close does not
+	// depend on resolving the network namespace of a pod that may be gone already
+	inodes map[string]uint64
+	mu     sync.Mutex
+}
+
+// NewServer creates a NetworkServiceServer that writes the DnsContext of connections with a kernel mechanism as
+// resolv.conf to configDir/<network namespace inode>/resolv.conf
+// The DnsContexts of all the connections into a network namespace are merged into a single file: name servers and
+// search domains are deduplicated and ordered by connection id.  The file is rewritten as connections come and go,
+// and removed along with its directory once the last connection into the network namespace is closed.  The NSC
+// (or a DNS proxy running next to it) finds its file by the inode of its network namespace, see
+// netnsinode.GetMyNetNSInodeNum.
+//             configDir - directory to write the resolver configurations to
+func NewServer(configDir string) networkservice.NetworkServiceServer {
+	return &dnsContextServer{
+		configDir: configDir,
+		configs:   make(map[uint64]map[string][]*networkservice.DNSConfig),
+		inodes:    make(map[string]uint64),
+	}
+}
+
+func (d *dnsContextServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conn := request.GetConnection()
+	if kernel.ToMechanism(conn.GetMechanism()) == nil || len(conn.GetContext().GetDnsContext().GetConfigs()) == 0 {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	d.mu.Lock()
+	inode, ok := d.inodes[conn.GetId()]
+	d.mu.Unlock()
+	if !ok {
+		var err error
+		if inode, err = netNSInode(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mu.Lock()
+	d.inodes[conn.GetId()] = inode
+	if d.configs[inode] == nil {
+		d.configs[inode] = make(map[string][]*networkservice.DNSConfig)
+	}
+	previous, existed := d.configs[inode][conn.GetId()]
+	d.configs[inode][conn.GetId()] = conn.GetContext().GetDnsContext().GetConfigs()
+	if err := d.write(inode); err != nil {
+		d.restore(inode, conn.GetId(), previous, existed)
+		d.mu.Unlock()
+		return nil, err
+	}
+	d.mu.Unlock()
+
+	rv, err := next.Server(ctx).Request(ctx, request)
+	if err != nil {
+		d.mu.Lock()
+		d.restore(inode, conn.GetId(), previous, existed)
+		if writeErr := d.write(inode); writeErr != nil {
+			log.Entry(ctx).Errorf("error restoring resolver configuration: %v", writeErr)
+		}
+		d.mu.Unlock()
+	}
+	return rv, err
+}
+
+func (d *dnsContextServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	if kernel.ToMechanism(conn.GetMechanism()) == nil {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	d.mu.Lock()
+	if inode, ok := d.inodes[conn.GetId()]; ok {
+		d.restore(inode, conn.GetId(), nil, false)
+		if err := d.write(inode); err != nil {
+			log.Entry(ctx).Errorf("error removing resolver configuration of connection %s: %v", conn.GetId(), err)
+		}
+	}
+	d.mu.Unlock()
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// restore sets the configs of connection connID back to previous, or removes them if they did not exist.  Must be
+// called with d.mu held.
+func (d *dnsContextServer) restore(inode uint64, connID string, previous []*networkservice.DNSConfig, existed bool) {
+	if existed {
+		d.configs[inode][connID] = previous
+		return
+	}
+	delete(d.configs[inode], connID)
+	delete(d.inodes, connID)
+	if len(d.configs[inode]) == 0 {
+		delete(d.configs, inode)
+	}
+}
+
+// write writes the merged resolver configuration of the network namespace with inode, or removes it if there are no
+// connections into that network namespace left.  Must be called with d.mu held.
+func (d *dnsContextServer) write(inode uint64) error {
+	dir := filepath.Join(d.configDir, strconv.FormatUint(inode, 10))
+	if len(d.configs[inode]) == 0 {
+		if err := os.RemoveAll(dir); err != nil {
+			return errors.Wrapf(err, "error removing %s", dir)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return errors.Wrapf(err, "error creating %s", dir)
+	}
+	// Write to a temporary file first, so that readers never see a partially written file
+	tmpFile, err := ioutil.TempFile(dir, ResolvConfFilename)
+	if err != nil {
+		return errors.Wrapf(err, "error creating temporary file in %s", dir)
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+	if _, err = tmpFile.WriteString(resolvConf(d.configs[inode])); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrapf(err, "error writing %s", tmpFile.Name())
+	}
+	if err = tmpFile.Close(); err != nil {
+		return errors.Wrapf(err, "error writing %s", tmpFile.Name())
+	}
+	if err = os.Chmod(tmpFile.Name(), resolvConfMode); err != nil {
+		return errors.Wrapf(err, "error setting the mode of %s", tmpFile.Name())
+	}
+	filename := filepath.Join(dir, ResolvConfFilename)
+	if err = os.Rename(tmpFile.Name(), filename); err != nil {
+		return errors.Wrapf(err, "error writing %s", filename)
+	}
+	return nil
+}
+
+// resolvConf returns the merged resolv.conf for the dns configs of connections, keyed by connection id
+func resolvConf(connConfigs map[string][]*networkservice.DNSConfig) string {
+	connIDs := make([]string, 0, len(connConfigs))
+	for connID := range connConfigs {
+		connIDs = append(connIDs, connID)
+	}
+	sort.Strings(connIDs)
+
+	var nameservers, searchDomains []string
+	for _, connID := range connIDs {
+		for _, config := range connConfigs[connID] {
+			nameservers = appendUnique(nameservers, config.GetDnsServerIps()...)
+			searchDomains = appendUnique(searchDomains, config.GetSearchDomains()...)
+		}
+	}
+
+	sb := &strings.Builder{}
+	_, _ = sb.WriteString("# Generated by Network Service Mesh\n")
+	for _, nameserver := range nameservers {
+		_, _ = fmt.Fprintf(sb, "nameserver %s\n", nameserver)
+	}
+	if len(searchDomains) > 0 {
+		_, _ = fmt.Fprintf(sb, "search %s\n", strings.Join(searchDomains, " "))
+	}
+	return sb.String()
+}
+
+func appendUnique(values []string, newValues ...string) []string {
+	for _, newValue := range newValues {
+		found := false
+		for _, value := range values {
+			if value == newValue {
+				found = true
+				break
+			}
+		}
+		if !found && newValue != "" {
+			values = append(values, newValue)
+		}
+	}
+	return values
+}
+
+func netNSInode(conn *networkservice.Connection) (uint64, error) {
+	netNS, err := netns.Resolve(kernel.ToMechanism(conn.GetMechanism()).GetNetNSURL())
+	if err != nil {
+		return 0, err
+	}
+	inode, err := netnsinode.GetNetNSInodeNum(netns.Filename(netNS))
+	if err != nil {
+		return 0, errors.Wrapf(err, "error getting the inode of network namespace %s", netns.Filename(netNS))
+	}
+	return inode, nil
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package dnscontext_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/dnscontext"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+func newConnection(id, netnsFilename string, configs ...*networkservice.DNSConfig) *networkservice.Connection {
+	return &networkservice.Connection{
+		Id: id,
+		Mechanism: &networkservice.Mechanism{
+			Cls:  cls.LOCAL,
+			Type: kernel.MECHANISM,
+			Parameters: map[string]string{
+				kernel.NetNSURL: (&url.URL{Scheme: "file", Path: netnsFilename}).String(),
+			},
+		},
+		Context: &networkservice.ConnectionContext{
+			DnsContext: &networkservice.DNSContext{
+				Configs: configs,
+			},
+		},
+	}
+}
+
+func TestDNSContextServer(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "dnscontext")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(configDir) }()
+
+	// Any file will do as the network namespace file, all that matters is its inode
+	netnsFile, err := ioutil.TempFile("", "netns")
+	require.NoError(t, err)
+	require.NoError(t, netnsFile.Close())
+	defer func() { _ = os.Remove(netnsFile.Name()) }()
+	inode, err := netnsinode.GetNetNSInodeNum(netnsFile.Name())
+	require.NoError(t, err)
+	resolvConf := filepath.Join(configDir, strconv.FormatUint(inode, 10), dnscontext.ResolvConfFilename)
+
+	server := next.NewNetworkServiceServer(dnscontext.NewServer(configDir))
+	conn1, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: newConnection("1", netnsFile.Name(), &networkservice.DNSConfig{
+			DnsServerIps:  []string{"10.0.0.1"},
+			SearchDomains: []string{"a.svc", "svc"},
+		}),
+	})
+	require.NoError(t, err)
+	conn2, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: newConnection("2", netnsFile.Name(), &networkservice.DNSConfig{
+			DnsServerIps:  []string{"10.0.1.1", "10.0.0.1"},
+			SearchDomains: []string{"b.svc", "svc"},
+		}),
+	})
+	require.NoError(t, err)
+
+	contents, err := ioutil.ReadFile(resolvConf)
+	require.NoError(t, err)
+	assert.Equal(t, "# Generated by Network Service Mesh\n"+
+		"nameserver 10.0.0.1\n"+
+		"nameserver 10.0.1.1\n"+
+		"search a.svc svc b.svc\n", string(contents))
+
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	contents, err = ioutil.ReadFile(resolvConf)
+	require.NoError(t, err)
+	assert.Equal(t, "# Generated by Network Service Mesh\n"+
+		"nameserver 10.0.1.1\n"+
+		"nameserver 10.0.0.1\n"+
+		"search b.svc svc\n", string(contents))
+
+	_, err = server.Close(context.Background(), conn2)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Dir(resolvConf))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDNSContextServer_NetNSGone(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "dnscontext")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(configDir) }()
+
+	netnsFile, err := ioutil.TempFile("", "netns")
+	require.NoError(t, err)
+	require.NoError(t, netnsFile.Close())
+	inode, err := netnsinode.GetNetNSInodeNum(netnsFile.Name())
+	require.NoError(t, err)
+	resolvConf := filepath.Join(configDir, strconv.FormatUint(inode, 10), dnscontext.ResolvConfFilename)
+
+	server := next.NewNetworkServiceServer(dnscontext.NewServer(configDir))
+	conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: newConnection("1", netnsFile.Name(), &networkservice.DNSConfig{
+			DnsServerIps: []string{"10.0.0.1"},
+		}),
+	})
+	require.NoError(t, err)
+	_, err = os.Stat(resolvConf)
+	require.NoError(t, err)
+
+	// The network namespace of a pod is gone by the time its connection is closed
+	require.NoError(t, os.Remove(netnsFile.Name()))
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Dir(resolvConf))
+	assert.True(t, os.IsNotExist(err))
+}
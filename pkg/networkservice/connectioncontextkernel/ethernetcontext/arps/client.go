@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+type setKernelArpsClient struct{}
+
+// NewClient provides a NetworkServiceClient that sets the arp entries for kernel linux config
+// The kernel interface leaving the Client owns the dst addresses of the connection, so a static neighbor entry
+// pointing at the DstMac of the other end is added for every src address of the connection.
+func NewClient() networkservice.NetworkServiceClient {
+	return &setKernelArpsClient{}
+}
+
+func (c *setKernelArpsClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	ctx = kernelctx.WithClientInterface(ctx)
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.addArpEntries(ctx, conn)
+	return conn, nil
+}
+
+func (c *setKernelArpsClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	ctx = kernelctx.WithClientInterface(ctx)
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.addArpEntries(ctx, conn)
+	return rv, nil
+}
+
+func (c *setKernelArpsClient) addArpEntries(ctx context.Context, conn *networkservice.Connection) {
+	config := vppagent.Config(ctx)
+	iface := kernelctx.ClientInterface(ctx)
+	dstMac := conn.GetContext().GetEthernetContext().GetDstMac()
+	if iface == nil || dstMac == "" {
+		return
+	}
+	config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
+		arpEntries(iface.GetName(), ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr()), dstMac)...)
+}
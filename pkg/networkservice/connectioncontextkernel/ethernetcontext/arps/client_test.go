@@ -0,0 +1,100 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package arps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+func TestClient_DualStack(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id: "1",
+		Mechanism: &networkservice.Mechanism{
+			Type: kernel.MECHANISM,
+		},
+		Context: &networkservice.ConnectionContext{
+			EthernetContext: &networkservice.EthernetContext{
+				SrcMac: "0a:1b:3c:4d:5e:6f",
+				DstMac: "0a:1b:3c:4d:5e:70",
+			},
+			IpContext: &networkservice.IPContext{
+				SrcIpAddr: "172.16.1.1/32,fd00::1/128",
+				DstIpAddr: "172.16.1.2/32,fd00::2/128",
+			},
+		},
+	}
+	var config *configurator.Config
+	client := next.NewNetworkServiceClient(
+		vppagent.NewClient(),
+		NewClient(),
+		&interfaceClient{name: "nsm-1", config: &config},
+	)
+	expected := []*linux.ARPEntry{
+		{Interface: "nsm-1", IpAddress: "172.16.1.1", HwAddress: "0a:1b:3c:4d:5e:70"},
+		{Interface: "nsm-1", IpAddress: "fd00::1", HwAddress: "0a:1b:3c:4d:5e:70"},
+	}
+	_, err := client.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	assert.Nil(t, err)
+	assert.Equal(t, expected, config.GetLinuxConfig().GetArpEntries())
+	_, err = client.Close(context.Background(), conn)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, config.GetLinuxConfig().GetArpEntries())
+}
+
+// interfaceClient plays the part of a kernel mechanism client, which appends the client interface on the way back
+type interfaceClient struct {
+	name   string
+	config **configurator.Config
+}
+
+func (i *interfaceClient) Request(ctx context.Context, in *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	i.appendInterface(ctx)
+	return conn, nil
+}
+
+func (i *interfaceClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	i.appendInterface(ctx)
+	return rv, nil
+}
+
+func (i *interfaceClient) appendInterface(ctx context.Context) {
+	*i.config = vppagent.Config(ctx)
+	iface := &linux.Interface{Name: i.name}
+	(*i.config).GetLinuxConfig().Interfaces = append((*i.config).GetLinuxConfig().GetInterfaces(), iface)
+	kernelctx.SetClientInterface(ctx, iface)
+}
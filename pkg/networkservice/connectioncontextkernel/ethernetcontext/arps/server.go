@@ -19,6 +19,7 @@ package arps
 
 import (
 	"context"
+	"net"
 	"strings"
 
 	"github.com/golang/protobuf/ptypes/empty"
@@ -27,42 +28,54 @@ import (
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
 type setKernelArpsServer struct{}
 
 // NewServer provides a NetworkServiceServer that sets the arp entry for kernel linux config
+// A static neighbor entry (ARP for IPv4, NDP for IPv6) pointing at the DstMac is added for every dst address of the
+// connection.
 func NewServer() networkservice.NetworkServiceServer {
 	return &setKernelArpsServer{}
 }
 
 func (s *setKernelArpsServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	config := vppagent.Config(ctx)
-	iface := kernelctx.ServerInterface(ctx)
-	if iface != nil && request.GetConnection().GetContext().GetEthernetContext().GetDstMac() != "" && request.GetConnection().GetContext().GetIpContext().GetDstIpAddr() != "" {
-		config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
-			&linux.ARPEntry{
-				IpAddress: strings.Split(request.GetConnection().GetContext().GetIpContext().GetDstIpAddr(), "/")[0],
-				Interface: iface.Name,
-				HwAddress: request.Connection.GetContext().GetEthernetContext().GetDstMac(),
-			},
-		)
-	}
+	s.addArpEntries(ctx, request.GetConnection())
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (s *setKernelArpsServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	s.addArpEntries(ctx, conn)
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func (s *setKernelArpsServer) addArpEntries(ctx context.Context, conn *networkservice.Connection) {
 	config := vppagent.Config(ctx)
 	iface := kernelctx.ServerInterface(ctx)
-	if iface != nil && conn.GetContext().GetEthernetContext().GetDstMac() != "" && conn.GetContext().GetIpContext().GetDstIpAddr() != "" {
-		config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
-			&linux.ARPEntry{
-				IpAddress: strings.Split(conn.GetContext().GetIpContext().GetDstIpAddr(), "/")[0],
-				Interface: iface.Name,
-				HwAddress: conn.GetContext().GetEthernetContext().GetDstMac(),
-			},
-		)
+	dstMac := conn.GetContext().GetEthernetContext().GetDstMac()
+	if iface == nil || dstMac == "" {
+		return
 	}
-	return next.Server(ctx).Close(ctx, conn)
+	config.GetLinuxConfig().ArpEntries = append(config.GetLinuxConfig().GetArpEntries(),
+		arpEntries(iface.GetName(), ipaddrs.Split(conn.GetContext().GetIpContext().GetDstIpAddr()), dstMac)...)
+}
+
+// arpEntries returns a static neighbor entry on ifaceName for each of ipAddrs.  ipAddrs may be given with or without a
+// prefix length.
+func arpEntries(ifaceName string, ipAddrs []string, hwAddress string) []*linux.ARPEntry {
+	var rv []*linux.ARPEntry
+	for _, ipAddr := range ipAddrs {
+		ip := net.ParseIP(strings.Split(ipAddr, "/")[0])
+		if ip == nil {
+			continue
+		}
+		rv = append(rv, &linux.ARPEntry{
+			IpAddress: ip.String(),
+			Interface: ifaceName,
+			HwAddress: hwAddress,
+		})
+	}
+	return rv
 }
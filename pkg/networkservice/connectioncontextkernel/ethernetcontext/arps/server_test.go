@@ -25,6 +25,7 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"github.com/stretchr/testify/assert"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
@@ -103,3 +104,45 @@ func (t *testingServer) Close(ctx context.Context, conn *networkservice.Connecti
 	assert.Equal(t, expectedArp, config.LinuxConfig.ArpEntries[0])
 	return result, err
 }
+
+func TestServer_DualStack(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id: "1",
+		Context: &networkservice.ConnectionContext{
+			EthernetContext: &networkservice.EthernetContext{
+				DstMac: "0a:1b:3c:4d:5e:6f",
+			},
+			IpContext: &networkservice.IPContext{
+				DstIpAddr: "172.16.1.2/32,fd00::2/128",
+			},
+		},
+	}
+	var config *configurator.Config
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		&interfaceServer{name: "nsm-1", config: &config},
+		NewServer(),
+	)
+	_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	assert.Nil(t, err)
+	assert.Equal(t, []*linux.ARPEntry{
+		{Interface: "nsm-1", IpAddress: "172.16.1.2", HwAddress: "0a:1b:3c:4d:5e:6f"},
+		{Interface: "nsm-1", IpAddress: "fd00::2", HwAddress: "0a:1b:3c:4d:5e:6f"},
+	}, config.GetLinuxConfig().GetArpEntries())
+}
+
+type interfaceServer struct {
+	name   string
+	config **configurator.Config
+}
+
+func (i *interfaceServer) Request(ctx context.Context, in *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	*i.config = vppagent.Config(ctx)
+	iface := &linux.Interface{Name: i.name}
+	(*i.config).GetLinuxConfig().Interfaces = append((*i.config).GetLinuxConfig().GetInterfaces(), iface)
+	return next.Server(ctx).Request(kernelctx.WithServerInterface(ctx, iface), in)
+}
+
+func (i *interfaceServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package getmac
+
+import "time"
+
+const defaultTimeout = 5 * time.Second
+
+// Option - option for getmac.NewServer
+type Option func(s *getMacKernelServer)
+
+// WithTimeout - sets the timeout for looking up the mac addresses from the vppagent.  Defaults to 5 seconds
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *getMacKernelServer) {
+		s.timeout = timeout
+	}
+}
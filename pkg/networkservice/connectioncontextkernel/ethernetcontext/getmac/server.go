@@ -19,55 +19,142 @@ package getmac
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
-	"github.com/sirupsen/logrus"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/trace"
+	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
 	"google.golang.org/grpc"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
+// FailuresMetric is the path segment metric counting the mac address lookups of the connection that failed
+const FailuresMetric = "getmac_failures"
+
 // NewServer creates a NetworkServiceServer chain element to set the EthernetContext for Kernel connection request
-func NewServer(сс grpc.ClientConnInterface) networkservice.NetworkServiceServer {
-	return &getMacKernelServer{
-		client: configurator.NewConfiguratorServiceClient(сс),
+// Once the Request has been committed it looks up the mac addresses actually assigned to the kernel interface (DstMac)
+// and to the vpp interface (SrcMac) of the connection.  The kernel interface is the one the kernel mechanism put into
+// the context (see kernelctx.ServerInterface), connections without one are left alone.  Lookup failures do not fail
+// the Request: they are logged and counted per connection in the FailuresMetric of the path segment.
+// Note: the DumpRequest of the configurator API can not be narrowed down to some of the items, so every lookup dumps
+// the whole vppagent config and picks the interfaces of the connection out of it.
+func NewServer(cc grpc.ClientConnInterface, options ...Option) networkservice.NetworkServiceServer {
+	rv := &getMacKernelServer{
+		client:   configurator.NewConfiguratorServiceClient(cc),
+		timeout:  defaultTimeout,
+		failures: make(map[string]uint64),
+	}
+	for _, opt := range options {
+		opt(rv)
 	}
+	return rv
 }
 
 type getMacKernelServer struct {
-	client configurator.ConfiguratorServiceClient
+	client  configurator.ConfiguratorServiceClient
+	timeout time.Duration
+	// failures - number of failed lookups, keyed by connection id
+	failures map[string]uint64
+	mu       sync.Mutex
 }
 
 func (s *getMacKernelServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	var dstInterface *linux.Interface
-	config := vppagent.Config(ctx)
-	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil && len(config.GetLinuxConfig().GetInterfaces()) > 0 {
-		dstInterface = config.LinuxConfig.Interfaces[len(config.LinuxConfig.Interfaces)-1]
+	var linuxIface *linux.Interface
+	var vppIface *vpp.Interface
+	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil {
+		linuxIface = kernelctx.ServerInterface(ctx)
+		vppIface = kernelctx.VppInterface(vppagent.Config(ctx).GetVppConfig(), linuxIface)
 	}
+	index := request.GetConnection().GetPath().GetIndex()
 	conn, err := next.Server(ctx).Request(ctx, request)
-	if err == nil && dstInterface != nil {
-		dump, dumpErr := s.client.Dump(context.Background(), &configurator.DumpRequest{})
-		if dumpErr != nil {
-			logrus.Errorf("An error during ConfiguratorClient.Dump, err: %v", dumpErr.Error())
-			return conn, err
-		}
-		for _, iface := range dump.Dump.LinuxConfig.Interfaces {
-			if iface.Name == dstInterface.Name {
-				request.GetConnection().GetContext().EthernetContext = &networkservice.EthernetContext{
-					DstMac: iface.PhysAddress,
-				}
-				break
+	if err != nil || linuxIface == nil {
+		return conn, err
+	}
+	if lookupErr := s.setMacs(ctx, conn, linuxIface, vppIface); lookupErr != nil {
+		s.mu.Lock()
+		s.failures[conn.GetId()]++
+		failures := s.failures[conn.GetId()]
+		s.mu.Unlock()
+		trace.Log(ctx).Errorf("getmac: %+v", lookupErr)
+		if segments := conn.GetPath().GetPathSegments(); int(index) < len(segments) {
+			if segments[index].Metrics == nil {
+				segments[index].Metrics = make(map[string]string)
 			}
+			segments[index].Metrics[FailuresMetric] = strconv.FormatUint(failures, 10)
 		}
 	}
-	return conn, err
+	return conn, nil
 }
 
 func (s *getMacKernelServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	s.mu.Lock()
+	delete(s.failures, conn.GetId())
+	s.mu.Unlock()
 	return next.Server(ctx).Close(ctx, conn)
 }
+
+// setMacs sets the DstMac and SrcMac of conn to the mac addresses the vppagent reports for linuxIface and vppIface
+func (s *getMacKernelServer) setMacs(ctx context.Context, conn *networkservice.Connection, linuxIface *linux.Interface, vppIface *vpp.Interface) error {
+	dumpCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	dump, err := s.client.Dump(dumpCtx, &configurator.DumpRequest{})
+	if err != nil {
+		return errors.Wrap(err, "failed to dump the vppagent config")
+	}
+	trace.Log(ctx).Debugf("getmac: looking up mac addresses of %q and %q", linuxIface.GetName(), vppIface.GetName())
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetEthernetContext() == nil {
+		conn.GetContext().EthernetContext = &networkservice.EthernetContext{}
+	}
+	ethernetContext := conn.GetContext().GetEthernetContext()
+	var missing []string
+	if linuxIface != nil {
+		if mac := linuxPhysAddress(dump.GetDump(), linuxIface.GetName()); mac != "" {
+			ethernetContext.DstMac = mac
+		} else {
+			missing = append(missing, "kernel interface "+linuxIface.GetName())
+		}
+	}
+	if vppIface != nil {
+		if mac := vppPhysAddress(dump.GetDump(), vppIface.GetName()); mac != "" {
+			ethernetContext.SrcMac = mac
+		} else {
+			missing = append(missing, "vpp interface "+vppIface.GetName())
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("no mac address found for %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func linuxPhysAddress(config *configurator.Config, name string) string {
+	for _, iface := range config.GetLinuxConfig().GetInterfaces() {
+		if iface.GetName() == name {
+			return iface.GetPhysAddress()
+		}
+	}
+	return ""
+}
+
+func vppPhysAddress(config *configurator.Config, name string) string {
+	for _, iface := range config.GetVppConfig().GetInterfaces() {
+		if iface.GetName() == name {
+			return iface.GetPhysAddress()
+		}
+	}
+	return ""
+}
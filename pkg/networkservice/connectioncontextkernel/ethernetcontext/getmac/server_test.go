@@ -18,6 +18,7 @@ package getmac
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	"github.com/golang/protobuf/ptypes/empty"
@@ -28,9 +29,12 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
 	"google.golang.org/grpc"
 )
 
@@ -53,14 +57,71 @@ func TestServerBasic(t *testing.T) {
 		vppagent.NewServer(),
 		&testingServer{t},
 		&getMacKernelServer{
-			client: &testDumpConfiguratorClient{},
+			client:   &testDumpConfiguratorClient{},
+			failures: make(map[string]uint64),
 		})
 	cc, err := server.Request(context.Background(), request)
 	assert.NoError(t, err)
 	assert.NotNil(t, cc)
 }
 
+func failureRequest(id string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: id,
+			Mechanism: &networkservice.Mechanism{
+				Type: kernel.MECHANISM,
+			},
+			Path: &networkservice.Path{
+				PathSegments: []*networkservice.PathSegment{{}},
+			},
+		},
+	}
+}
+
+func TestServer_DumpFailure(t *testing.T) {
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		&interfaceServer{},
+		&getMacKernelServer{
+			client:   &testDumpConfiguratorClient{err: errors.New("unavailable")},
+			timeout:  defaultTimeout,
+			failures: make(map[string]uint64),
+		})
+	// Failures are counted per connection
+	for i := 1; i <= 2; i++ {
+		conn, err := server.Request(context.Background(), failureRequest("1"))
+		assert.NoError(t, err)
+		assert.Empty(t, conn.GetContext().GetEthernetContext().GetDstMac())
+		assert.Equal(t, strconv.Itoa(i), conn.GetPath().GetPathSegments()[0].GetMetrics()[FailuresMetric])
+	}
+	conn, err := server.Request(context.Background(), failureRequest("2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", conn.GetPath().GetPathSegments()[0].GetMetrics()[FailuresMetric])
+
+	// and forgotten on Close
+	_, err = server.Close(context.Background(), conn)
+	assert.NoError(t, err)
+	conn, err = server.Request(context.Background(), failureRequest("2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", conn.GetPath().GetPathSegments()[0].GetMetrics()[FailuresMetric])
+}
+
+func TestServer_NoKernelInterface(t *testing.T) {
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		&getMacKernelServer{
+			client:   &testDumpConfiguratorClient{err: errors.New("unexpected dump")},
+			timeout:  defaultTimeout,
+			failures: make(map[string]uint64),
+		})
+	conn, err := server.Request(context.Background(), failureRequest("1"))
+	assert.NoError(t, err)
+	assert.Empty(t, conn.GetPath().GetPathSegments()[0].GetMetrics()[FailuresMetric])
+}
+
 type testDumpConfiguratorClient struct {
+	err error
 }
 
 func (t *testDumpConfiguratorClient) Get(ctx context.Context, in *configurator.GetRequest, opts ...grpc.CallOption) (*configurator.GetResponse, error) {
@@ -76,6 +137,12 @@ func (t *testDumpConfiguratorClient) Delete(ctx context.Context, in *configurato
 }
 
 func (t *testDumpConfiguratorClient) Dump(ctx context.Context, in *configurator.DumpRequest, opts ...grpc.CallOption) (*configurator.DumpResponse, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, errors.New("dump without a deadline")
+	}
 	return &configurator.DumpResponse{
 		Dump: &configurator.Config{
 			LinuxConfig: &linux.ConfigData{
@@ -98,6 +165,14 @@ func (t *testDumpConfiguratorClient) Dump(ctx context.Context, in *configurator.
 					},
 				},
 			},
+			VppConfig: &vpp.ConfigData{
+				Interfaces: []*vpp.Interface{
+					{
+						Name:        "DST-1-tap",
+						PhysAddress: "4a-1b-3c-4d-5e-6f",
+					},
+				},
+			},
 		},
 	}, nil
 }
@@ -120,6 +195,18 @@ func (t *testingServer) Request(ctx context.Context, in *networkservice.NetworkS
 			},
 			{
 				Name: "DST-1",
+				Link: &linuxinterfaces.Interface_Tap{
+					Tap: &linuxinterfaces.TapLink{
+						VppTapIfName: "DST-1-tap",
+					},
+				},
+			},
+		},
+	}
+	config.VppConfig = &vpp.ConfigData{
+		Interfaces: []*vpp.Interface{
+			{
+				Name: "DST-1-tap",
 			},
 		},
 	}
@@ -128,9 +215,24 @@ func (t *testingServer) Request(ctx context.Context, in *networkservice.NetworkS
 	assert.Nil(t, err)
 	assert.NotNil(t, conn.GetContext().GetEthernetContext())
 	assert.Equal(t, conn.GetContext().GetEthernetContext().GetDstMac(), "0a-1b-3c-4d-5e-6f")
+	assert.Equal(t, conn.GetContext().GetEthernetContext().GetSrcMac(), "4a-1b-3c-4d-5e-6f")
 	return conn, err
 }
 
 func (t *testingServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	return new(empty.Empty), nil
 }
+
+type interfaceServer struct{}
+
+func (i *interfaceServer) Request(ctx context.Context, in *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	config := vppagent.Config(ctx)
+	iface := &linux.Interface{Name: "DST-1"}
+	config.GetLinuxConfig().Interfaces = append(config.GetLinuxConfig().GetInterfaces(), iface)
+	ctx = kernelctx.WithServerInterface(ctx, iface)
+	return next.Server(ctx).Request(ctx, in)
+}
+
+func (i *interfaceServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -29,7 +29,7 @@ import (
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
-	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
 type setKernelMacClient struct{}
@@ -62,19 +62,25 @@ func NewClient() networkservice.NetworkServiceClient {
 }
 
 func (c *setKernelMacClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
-	config := vppagent.Config(ctx)
-	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil && len(config.GetLinuxConfig().GetInterfaces()) > 0 {
-		current := len(config.LinuxConfig.Interfaces) - 1
-		config.LinuxConfig.Interfaces[current].PhysAddress = request.GetConnection().GetContext().GetEthernetContext().GetSrcMac()
+	ctx = kernelctx.WithClientInterface(ctx)
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return next.Client(ctx).Request(ctx, request, opts...)
+	if iface := kernelctx.ClientInterface(ctx); iface != nil && kernel.ToMechanism(conn.GetMechanism()) != nil {
+		iface.PhysAddress = conn.GetContext().GetEthernetContext().GetSrcMac()
+	}
+	return conn, nil
 }
 
 func (c *setKernelMacClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
-	config := vppagent.Config(ctx)
-	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil && len(config.GetLinuxConfig().GetInterfaces()) > 0 {
-		current := len(config.LinuxConfig.Interfaces) - 1
-		config.LinuxConfig.Interfaces[current].PhysAddress = conn.GetContext().GetEthernetContext().GetSrcMac()
+	ctx = kernelctx.WithClientInterface(ctx)
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if iface := kernelctx.ClientInterface(ctx); iface != nil && kernel.ToMechanism(conn.GetMechanism()) != nil {
+		iface.PhysAddress = conn.GetContext().GetEthernetContext().GetSrcMac()
 	}
-	return next.Client(ctx).Close(ctx, conn, opts...)
+	return rv, nil
 }
@@ -30,6 +30,7 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
 func TestClientBasic(t *testing.T) {
@@ -41,50 +42,49 @@ func TestClientBasic(t *testing.T) {
 			},
 			Context: &networkservice.ConnectionContext{
 				EthernetContext: &networkservice.EthernetContext{
-					DstMac: "0a-1b-3c-4d-5e-6f",
+					SrcMac: "0a:1b:3c:4d:5e:6f",
 				},
 			},
 		},
 	}
-	server := next.NewNetworkServiceClient(vppagent.NewClient(), &testingClient{t}, NewClient())
-	_, _ = server.Request(context.Background(), request)
-	_, _ = server.Close(context.Background(), request.Connection)
+	mechanism := &testingClient{}
+	client := next.NewNetworkServiceClient(vppagent.NewClient(), NewClient(), mechanism)
+	_, err := client.Request(context.Background(), request)
+	assert.Nil(t, err)
+	assert.Equal(t, "0a:1b:3c:4d:5e:6f", mechanism.iface.GetPhysAddress())
+	_, err = client.Close(context.Background(), request.Connection)
+	assert.Nil(t, err)
+	assert.Equal(t, "0a:1b:3c:4d:5e:6f", mechanism.iface.GetPhysAddress())
 }
 
+// testingClient plays the part of a kernel mechanism client, which appends the client interface on the way back
 type testingClient struct {
-	*testing.T
+	iface *linux.Interface
 }
 
 func (t *testingClient) Request(ctx context.Context, in *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
-	config := vppagent.Config(ctx)
-	assert.NotNil(t, config)
-	targetInterface := &linux.Interface{
-		Name: "SRC-1",
-	}
-	config.LinuxConfig = &linux.ConfigData{
-		Interfaces: []*linux.Interface{
-			targetInterface,
-		},
-	}
 	conn, err := next.Client(ctx).Request(ctx, in, opts...)
-	assert.Nil(t, err)
-	assert.Equal(t, targetInterface.PhysAddress, conn.GetContext().GetEthernetContext().GetSrcMac())
-	return conn, err
+	if err != nil {
+		return nil, err
+	}
+	t.appendInterface(ctx)
+	return conn, nil
 }
 
 func (t *testingClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
-	config := vppagent.Config(ctx)
-	assert.NotNil(t, config)
-	targetInterface := &linux.Interface{
-		Name: "SRC-1",
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
 	}
-	config.LinuxConfig = &linux.ConfigData{
-		Interfaces: []*linux.Interface{
-			targetInterface,
-		},
+	t.appendInterface(ctx)
+	return rv, nil
+}
+
+func (t *testingClient) appendInterface(ctx context.Context) {
+	config := vppagent.Config(ctx)
+	t.iface = &linux.Interface{
+		Name: "client-1",
 	}
-	result, err := next.Client(ctx).Close(ctx, conn, opts...)
-	assert.Nil(t, err)
-	assert.Equal(t, targetInterface.PhysAddress, conn.GetContext().GetEthernetContext().GetSrcMac())
-	return result, err
+	config.GetLinuxConfig().Interfaces = append(config.GetLinuxConfig().GetInterfaces(), t.iface)
+	kernelctx.SetClientInterface(ctx, t.iface)
 }
@@ -28,6 +28,9 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
 type setIPKernelClient struct{}
@@ -36,6 +39,13 @@ type setIPKernelClient struct{}
 // It sets the IP Address on the *kernel* side of an interface leaving the
 // Client.  Generally only used by privileged Clients like those implementing
 // the Cross Connect Network Service for K8s (formerly known as NSM Forwarder).
+// The dst address may be a comma separated list of addresses (see ipaddrs), all of which are added to the addresses
+// already set on the interface.  The addresses are allocated in the netalloc config and the interface refers to the
+// allocations (see ipalloc).
+// IPv6 link-local addresses are skipped, the kernel generates the link-local address of an interface itself.
+// Note: the kernel runs duplicate address detection (DAD) for IPv6 addresses, so they are tentative (unusable) for a
+// moment after the interface comes up.  vppagent can not skip DAD for an address, applications which need IPv6 right
+// away should disable it for the interface (net.ipv6.conf.<interface>.accept_dad=0).
 //                                         Client
 //                              +---------------------------+
 //                              |                           |
@@ -60,33 +70,29 @@ func NewClient() networkservice.NetworkServiceClient {
 }
 
 func (s *setIPKernelClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	ctx = kernelctx.WithClientInterface(ctx)
 	conn, err := next.Client(ctx).Request(ctx, request, opts...)
 	if err != nil {
 		return nil, err
 	}
-	conf := vppagent.Config(ctx)
-	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil && len(conf.GetLinuxConfig().GetInterfaces()) > 0 {
-		index := len(conf.GetLinuxConfig().GetInterfaces()) - 1
-		dstIP := conn.GetContext().GetIpContext().GetDstIpAddr()
-		if dstIP != "" {
-			conf.GetLinuxConfig().GetInterfaces()[index].IpAddresses = []string{dstIP}
-		}
-	}
+	s.appendIPAddresses(ctx, conn)
 	return conn, nil
 }
 
 func (s *setIPKernelClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	ctx = kernelctx.WithClientInterface(ctx)
 	e, err := next.Client(ctx).Close(ctx, conn, opts...)
 	if err != nil {
 		return nil, err
 	}
-	conf := vppagent.Config(ctx)
-	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil && len(conf.GetLinuxConfig().GetInterfaces()) > 0 {
-		index := len(conf.GetLinuxConfig().GetInterfaces()) - 1
-		dstIP := conn.GetContext().GetIpContext().GetDstIpAddr()
-		if dstIP != "" {
-			conf.GetLinuxConfig().GetInterfaces()[index].IpAddresses = []string{conn.GetContext().GetIpContext().GetDstIpAddr()}
-		}
-	}
+	s.appendIPAddresses(ctx, conn)
 	return e, err
 }
+
+func (s *setIPKernelClient) appendIPAddresses(ctx context.Context, conn *networkservice.Connection) {
+	if iface := kernelctx.ClientInterface(ctx); iface != nil && kernel.ToMechanism(conn.GetMechanism()) != nil {
+		dstIPs := ipaddrs.WithoutLinkLocal(ipaddrs.Split(conn.GetContext().GetIpContext().GetDstIpAddr()))
+		allocations := ipalloc.Allocations(conn.GetId(), iface.GetName(), dstIPs, ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr()))
+		iface.IpAddresses = ipaddrs.Append(iface.GetIpAddresses(), ipalloc.Append(vppagent.Config(ctx).GetNetallocConfig(), allocations...)...)
+	}
+}
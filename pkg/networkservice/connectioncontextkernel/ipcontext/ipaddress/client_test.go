@@ -37,6 +37,7 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
 )
 
 const (
@@ -70,9 +71,10 @@ func TestSetIPKernelClient(t *testing.T) {
 			conf := vppagent.Config(ctx)
 			numInterfaces := len(conf.GetVppConfig().GetInterfaces())
 			require.Greater(t, numInterfaces, 0)
-			numIps := len(conf.GetLinuxConfig().GetInterfaces()[numInterfaces-1].GetIpAddresses())
-			require.Greater(t, numIps, 0)
-			assert.Equal(t, IPAddress, conf.GetLinuxConfig().GetInterfaces()[numInterfaces-1].GetIpAddresses()[0])
+			iface := conf.GetLinuxConfig().GetInterfaces()[numInterfaces-1]
+			require.Greater(t, len(iface.GetIpAddresses()), 0)
+			assert.Equal(t, ipalloc.Ref("-ipv4-0", iface.GetName()), iface.GetIpAddresses()[0])
+			assert.Equal(t, IPAddress+"/32", conf.GetNetallocConfig().GetIpAddresses()[0].GetAddress())
 		}),
 		ipaddress.NewClient(),
 		checkcontext.NewClient(t, func(t *testing.T, ctx context.Context) {
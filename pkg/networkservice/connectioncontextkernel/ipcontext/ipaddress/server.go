@@ -24,6 +24,9 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
@@ -33,6 +36,13 @@ type setIPKernelServer struct{}
 // It sets the IP Address on the *kernel* side of an interface plugged into the
 // Endpoint.  Generally only used by privileged Endpoints like those implementing
 // the Cross Connect Network Service for K8s (formerly known as NSM Forwarder).
+// The src address may be a comma separated list of addresses (see ipaddrs), all of which are added to the addresses
+// already set on the interface.  The addresses are allocated in the netalloc config and the interface refers to the
+// allocations (see ipalloc).
+// IPv6 link-local addresses are skipped, the kernel generates the link-local address of an interface itself.
+// Note: the kernel runs duplicate address detection (DAD) for IPv6 addresses, so they are tentative (unusable) for a
+// moment after the interface comes up.  vppagent can not skip DAD for an address, applications which need IPv6 right
+// away should disable it for the interface (net.ipv6.conf.<interface>.accept_dad=0).
 //                                         Endpoint
 //                              +---------------------------+
 //                              |                           |
@@ -57,23 +67,19 @@ func NewServer() networkservice.NetworkServiceServer {
 }
 
 func (s *setIPKernelServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	iface := kernelctx.ServerInterface(ctx)
-	if iface != nil {
-		srcIP := request.GetConnection().GetContext().GetIpContext().GetSrcIpAddr()
-		if srcIP != "" {
-			iface.IpAddresses = append(iface.GetIpAddresses(), srcIP)
-		}
-	}
+	s.appendIPAddresses(ctx, request.GetConnection())
 	return next.Server(ctx).Request(ctx, request)
 }
 
 func (s *setIPKernelServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
-	iface := kernelctx.ServerInterface(ctx)
-	if iface != nil {
-		srcIP := conn.GetContext().GetIpContext().GetSrcIpAddr()
-		if srcIP != "" {
-			iface.IpAddresses = append(iface.GetIpAddresses(), srcIP)
-		}
-	}
+	s.appendIPAddresses(ctx, conn)
 	return next.Server(ctx).Close(ctx, conn)
 }
+
+func (s *setIPKernelServer) appendIPAddresses(ctx context.Context, conn *networkservice.Connection) {
+	if iface := kernelctx.ServerInterface(ctx); iface != nil {
+		srcIPs := ipaddrs.WithoutLinkLocal(ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr()))
+		allocations := ipalloc.Allocations(conn.GetId(), iface.GetName(), srcIPs, ipaddrs.Split(conn.GetContext().GetIpContext().GetDstIpAddr()))
+		iface.IpAddresses = ipaddrs.Append(iface.GetIpAddresses(), ipalloc.Append(vppagent.Config(ctx).GetNetallocConfig(), allocations...)...)
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
 )
 
 func serverRequest() *networkservice.NetworkServiceRequest {
@@ -70,9 +71,10 @@ func TestSetIPKernelServer_Request(t *testing.T) {
 	conf := vppagent.Config(ctx)
 	numInterfaces := len(conf.GetLinuxConfig().GetInterfaces())
 	require.Greater(t, numInterfaces, 0)
-	ipAddresses := conf.GetLinuxConfig().GetInterfaces()[numInterfaces-1].GetIpAddresses()
-	require.Greater(t, len(ipAddresses), 0)
-	assert.Equal(t, IPAddress, ipAddresses[0])
+	iface := conf.GetLinuxConfig().GetInterfaces()[numInterfaces-1]
+	require.Greater(t, len(iface.GetIpAddresses()), 0)
+	assert.Equal(t, ipalloc.Ref("-ipv4-0", iface.GetName()), iface.GetIpAddresses()[0])
+	assert.Equal(t, IPAddress+"/32", conf.GetNetallocConfig().GetIpAddresses()[0].GetAddress())
 }
 
 func TestSetIPKernelServer_Close(t *testing.T) {
@@ -89,9 +91,10 @@ func TestSetIPKernelServer_Close(t *testing.T) {
 	conf := vppagent.Config(ctx)
 	numInterfaces := len(conf.GetLinuxConfig().GetInterfaces())
 	require.Greater(t, numInterfaces, 0)
-	ipAddresses := conf.GetLinuxConfig().GetInterfaces()[numInterfaces-1].GetIpAddresses()
-	require.Greater(t, len(ipAddresses), 0)
-	assert.Equal(t, IPAddress, ipAddresses[0])
+	iface := conf.GetLinuxConfig().GetInterfaces()[numInterfaces-1]
+	require.Greater(t, len(iface.GetIpAddresses()), 0)
+	assert.Equal(t, ipalloc.Ref("-ipv4-0", iface.GetName()), iface.GetIpAddresses()[0])
+	assert.Equal(t, IPAddress+"/32", conf.GetNetallocConfig().GetIpAddresses()[0].GetAddress())
 }
 
 func TestSetIPKernelServerPropagatesError(t *testing.T) {
@@ -105,3 +108,25 @@ func TestSetIPKernelServerPropagatesError(t *testing.T) {
 	_, err = server.Close(vppagent.WithConfig(context.Background()), serverRequest().GetConnection())
 	assert.NotNil(t, err)
 }
+
+func TestSetIPKernelServer_DualStack(t *testing.T) {
+	logrus.SetOutput(ioutil.Discard)
+	server := chain.NewNetworkServiceServer(
+		kerneltap.NewServer(),
+		ipaddress.NewServer(),
+	)
+	request := serverRequest()
+	request.GetConnection().GetContext().GetIpContext().SrcIpAddr = "10.0.0.2/32,fe80::2/64,fd00::2/128"
+	ctx := vppagent.WithConfig(context.Background())
+	_, err := server.Request(ctx, request)
+	require.NoError(t, err)
+
+	conf := vppagent.Config(ctx)
+	numInterfaces := len(conf.GetLinuxConfig().GetInterfaces())
+	require.Greater(t, numInterfaces, 0)
+	iface := conf.GetLinuxConfig().GetInterfaces()[numInterfaces-1]
+	assert.Equal(t, []string{ipalloc.Ref("-ipv4-0", iface.GetName()), ipalloc.Ref("-ipv6-0", iface.GetName())}, iface.GetIpAddresses())
+	require.Len(t, conf.GetNetallocConfig().GetIpAddresses(), 2)
+	assert.Equal(t, "10.0.0.2/32", conf.GetNetallocConfig().GetIpAddresses()[0].GetAddress())
+	assert.Equal(t, "fd00::2/128", conf.GetNetallocConfig().GetIpAddresses()[1].GetAddress())
+}
@@ -29,13 +29,16 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
 type setKernelRouteClient struct{}
 
 // NewClient creates a NetworkServiceClient that will put the routes from the connection context into
 //  the kernel network namespace kernel interface being inserted iff the
-//  selected mechanism for the connection is a kernel mechanism
+//  selected mechanism for the connection is a kernel mechanism.  DstRoutes are routed via the src address of the
+//  same family, and the src networks are reachable directly on the link
 //             Client
 //  +- - - - - - - - - - - - - - - -+         +---------------------------+
 //  |                               |         |  kernel network namespace |
@@ -60,6 +63,7 @@ func NewClient() networkservice.NetworkServiceClient {
 }
 
 func (s *setKernelRouteClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	ctx = kernelctx.WithClientInterface(ctx)
 	rv, err := next.Client(ctx).Request(ctx, request, opts...)
 	if err != nil {
 		return nil, err
@@ -69,6 +73,7 @@ func (s *setKernelRouteClient) Request(ctx context.Context, request *networkserv
 }
 
 func (s *setKernelRouteClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	ctx = kernelctx.WithClientInterface(ctx)
 	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
 	if err != nil {
 		return nil, err
@@ -79,17 +84,31 @@ func (s *setKernelRouteClient) Close(ctx context.Context, conn *networkservice.C
 
 func (s *setKernelRouteClient) addRoutes(ctx context.Context, conn *networkservice.Connection) {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		if conn.GetContext().GetIpContext().GetSrcIpAddr() == "" {
+		iface := kernelctx.ClientInterface(ctx)
+		if iface == nil {
 			return
 		}
-		srcIP, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
-		if err != nil {
-			return
+		srcIPAddrs := ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr())
+		duplicatedPrefixes := make(map[string]bool)
+		for _, route := range conn.GetContext().GetIpContext().GetDstRoutes() {
+			if _, ok := duplicatedPrefixes[route.Prefix]; !ok {
+				duplicatedPrefixes[route.Prefix] = true
+				vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
+					DstNetwork:        route.Prefix,
+					OutgoingInterface: iface.GetName(),
+					Scope:             linuxl3.Route_GLOBAL,
+					GwAddr:            gwAddr(vppagent.Config(ctx).GetNetallocConfig(), iface.GetName(), srcIPAddrs, route.Prefix),
+				})
+			}
 		}
-		conf := vppagent.Config(ctx)
-		index := len(conf.GetLinuxConfig().GetInterfaces()) - 1
-		if index >= 0 && srcIP.IsGlobalUnicast() {
-			iface := conf.GetLinuxConfig().GetInterfaces()[index]
+		for _, srcIPAddr := range srcIPAddrs {
+			srcIP, srcNet, err := net.ParseCIDR(srcIPAddr)
+			if err != nil || !srcIP.IsGlobalUnicast() {
+				continue
+			}
+			if _, ok := duplicatedPrefixes[srcNet.String()]; ok {
+				continue
+			}
 			vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
 				DstNetwork:        srcNet.String(),
 				OutgoingInterface: iface.GetName(),
@@ -0,0 +1,91 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxl3 "go.ligato.io/vpp-agent/v3/proto/ligato/linux/l3"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+func TestClient_DstRoutes(t *testing.T) {
+	conn := &networkservice.Connection{
+		Id: "1",
+		Mechanism: &networkservice.Mechanism{
+			Type: kernel.MECHANISM,
+		},
+		Context: &networkservice.ConnectionContext{
+			IpContext: &networkservice.IPContext{
+				SrcIpAddr: "172.16.1.1/30,fd00::1/126",
+				DstIpAddr: "172.16.1.2/30,fd00::2/126",
+				DstRoutes: []*networkservice.Route{
+					{Prefix: "10.0.0.0/8"},
+					{Prefix: "10.0.0.0/8"},
+					{Prefix: "fd10::/64"},
+				},
+			},
+		},
+	}
+	var config *configurator.Config
+	client := next.NewNetworkServiceClient(
+		vppagent.NewClient(),
+		NewClient(),
+		&interfaceClient{name: "nsm-1", config: &config},
+	)
+	_, err := client.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	assert.Nil(t, err)
+	assert.Equal(t, []*linux.Route{
+		{DstNetwork: "10.0.0.0/8", OutgoingInterface: "nsm-1", Scope: linuxl3.Route_GLOBAL, GwAddr: "172.16.1.1"},
+		{DstNetwork: "fd10::/64", OutgoingInterface: "nsm-1", Scope: linuxl3.Route_GLOBAL, GwAddr: "fd00::1"},
+		{DstNetwork: "172.16.1.0/30", OutgoingInterface: "nsm-1", Scope: linuxl3.Route_LINK},
+		{DstNetwork: "fd00::/126", OutgoingInterface: "nsm-1", Scope: linuxl3.Route_LINK},
+	}, config.GetLinuxConfig().GetRoutes())
+}
+
+// interfaceClient plays the part of a kernel mechanism client, which appends the client interface on the way back
+type interfaceClient struct {
+	name   string
+	config **configurator.Config
+}
+
+func (i *interfaceClient) Request(ctx context.Context, in *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	*i.config = vppagent.Config(ctx)
+	iface := &linux.Interface{Name: i.name}
+	(*i.config).GetLinuxConfig().Interfaces = append((*i.config).GetLinuxConfig().GetInterfaces(), iface)
+	kernelctx.SetClientInterface(ctx, iface)
+	return conn, nil
+}
+
+func (i *interfaceClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
@@ -25,11 +25,14 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
 	linuxl3 "go.ligato.io/vpp-agent/v3/proto/ligato/linux/l3"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/netalloc"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
 )
 
@@ -72,32 +75,42 @@ func (s *setKernelRoute) Close(ctx context.Context, conn *networkservice.Connect
 }
 
 func (s *setKernelRoute) addRoutes(ctx context.Context, conn *networkservice.Connection) {
-	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		duplicatedPrefixes := make(map[string]bool)
-		for _, route := range conn.GetContext().GetIpContext().GetSrcRoutes() {
-			if _, ok := duplicatedPrefixes[route.Prefix]; !ok {
-				duplicatedPrefixes[route.Prefix] = true
-				vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
-					DstNetwork:        route.Prefix,
-					OutgoingInterface: vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()[0].GetName(),
-					Scope:             linuxl3.Route_GLOBAL,
-					GwAddr:            extractCleanIPAddress(conn.GetContext().GetIpContext().GetDstIpAddr()),
-				})
-			}
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism == nil {
+		return
+	}
+	iface := kernelctx.ServerInterface(ctx)
+	if iface == nil {
+		return
+	}
+	linuxConfig := vppagent.Config(ctx).GetLinuxConfig()
+	srcIPAddrs := ipaddrs.Split(conn.GetContext().GetIpContext().GetSrcIpAddr())
+	dstIPAddrs := ipaddrs.Split(conn.GetContext().GetIpContext().GetDstIpAddr())
+	duplicatedPrefixes := make(map[string]bool)
+	for _, route := range conn.GetContext().GetIpContext().GetSrcRoutes() {
+		if _, ok := duplicatedPrefixes[route.Prefix]; !ok {
+			duplicatedPrefixes[route.Prefix] = true
+			linuxConfig.Routes = append(linuxConfig.Routes, &linux.Route{
+				DstNetwork:        route.Prefix,
+				OutgoingInterface: iface.GetName(),
+				Scope:             linuxl3.Route_GLOBAL,
+				GwAddr:            gwAddr(vppagent.Config(ctx).GetNetallocConfig(), iface.GetName(), dstIPAddrs, route.Prefix),
+			})
 		}
-		_, srcNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetSrcIpAddr())
+	}
+	for _, dstIPAddr := range dstIPAddrs {
+		dstIP, dstNet, err := net.ParseCIDR(dstIPAddr)
 		if err != nil {
-			return
+			continue
 		}
-		dstIP, dstNet, err := net.ParseCIDR(conn.GetContext().GetIpContext().GetDstIpAddr())
-		if err != nil {
-			return
+		srcNet := ipaddrs.OfFamily(srcIPAddrs, dstIP)
+		if srcNet == nil {
+			continue
 		}
 		if _, ok := duplicatedPrefixes[dstNet.String()]; ok || srcNet.Contains(dstIP) {
-			return
+			continue
 		}
-		if iface := kernelctx.ServerInterface(ctx); iface != nil && dstIP.IsGlobalUnicast() {
-			vppagent.Config(ctx).GetLinuxConfig().Routes = append(vppagent.Config(ctx).GetLinuxConfig().Routes, &linux.Route{
+		if dstIP.IsGlobalUnicast() {
+			linuxConfig.Routes = append(linuxConfig.Routes, &linux.Route{
 				DstNetwork:        dstNet.String(),
 				OutgoingInterface: iface.GetName(),
 				Scope:             linuxl3.Route_LINK,
@@ -106,10 +119,18 @@ func (s *setKernelRoute) addRoutes(ctx context.Context, conn *networkservice.Con
 	}
 }
 
-func extractCleanIPAddress(addr string) string {
-	ip, _, err := net.ParseCIDR(addr)
-	if err == nil {
-		return ip.String()
+// gwAddr returns the reference to the gateway allocated for ifaceName of the same address family as prefix, falling
+// back to the address from ipAddrs of that family
+func gwAddr(config *netalloc.ConfigData, ifaceName string, ipAddrs []string, prefix string) string {
+	prefixIP, _, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return ""
+	}
+	if ref := ipalloc.GWRef(config, ifaceName, prefixIP); ref != "" {
+		return ref
+	}
+	if ipNet := ipaddrs.OfFamily(ipAddrs, prefixIP); ipNet != nil {
+		return ipNet.IP.String()
 	}
-	return addr
+	return ""
 }
@@ -0,0 +1,34 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package connectioncontextkernel
+
+type serverOptions struct {
+	dnsConfigDir string
+}
+
+// Option - option for connectioncontextkernel.NewServer
+type Option func(o *serverOptions)
+
+// WithDNSConfigDir - enables writing the DnsContext of connections as resolv.conf files under dnsConfigDir, see
+// dnscontext.NewServer
+func WithDNSConfigDir(dnsConfigDir string) Option {
+	return func(o *serverOptions) {
+		o.dnsConfigDir = dnsConfigDir
+	}
+}
@@ -14,6 +14,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// +build !windows
+
 package connectioncontextkernel
 
 import (
@@ -21,6 +23,7 @@ import (
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/dnscontext"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ethernetcontext/macaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/ipaddress"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontextkernel/ipcontext/routes"
@@ -49,11 +52,19 @@ import (
 //                                            |                           |
 //                                            +---------------------------+
 //
-func NewServer() networkservice.NetworkServiceServer {
-	return chain.NewNetworkServiceServer(
+// The DnsContext is only applied if a directory for it is set with WithDNSConfigDir.
+func NewServer(opts ...Option) networkservice.NetworkServiceServer {
+	o := &serverOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	servers := []networkservice.NetworkServiceServer{
 		ipaddress.NewServer(),
 		macaddress.NewServer(),
-		// Note: routes are only applicable in this circumstance in the server side
 		routes.NewServer(),
-	)
+	}
+	if o.dnsConfigDir != "" {
+		servers = append(servers, dnscontext.NewServer(o.dnsConfigDir))
+	}
+	return chain.NewNetworkServiceServer(servers...)
 }
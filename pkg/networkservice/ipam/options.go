@@ -0,0 +1,28 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam
+
+// Option - option for ipam.NewServer
+type Option func(s *ipamServer)
+
+// WithPairMasks - sets the masks of the allocated addresses to the mask of the pair (/31 for IPv4, /127 for IPv6)
+// instead of host masks (/32 for IPv4, /128 for IPv6)
+func WithPairMasks() Option {
+	return func(s *ipamServer) {
+		s.pairMasks = true
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipam provides networkservice chain elements for allocating the addresses of connections
+package ipam
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipam"
+)
+
+type ipamServer struct {
+	pools     []*ipam.Pool
+	pairMasks bool
+	// allocations - addresses allocated to connections, keyed by connection id
+	allocations map[string]*allocation
+	mu          sync.Mutex
+}
+
+// allocation - addresses allocated to a connection
+type allocation struct {
+	// blocks - blocks allocated to or reserved for the connection in the pools
+	blocks []*net.IPNet
+	// srcIPAddr, dstIPAddr - the allocated addresses in CIDR notation, empty if given in the request instead
+	srcIPAddr string
+	dstIPAddr string
+}
+
+// NewServer creates a NetworkServiceServer chain element that allocates the src and dst addresses of connections
+// Each connection gets a pair of addresses (a /31 for IPv4 or a /127 for IPv6) from the first of pools with a free
+// pair not overlapping the ExcludedPrefixes of the request.  The lower address of the pair is the src address, the
+// upper one the dst address.  Allocations are kept per connection id, so refreshes get the same addresses, and are
+// released on Close.  Addresses already set in the request are kept and, if they belong to one of pools, reserved in
+// it until Close, so they are never allocated to another connection.  If only one of the src and dst addresses is set,
+// only the other one is allocated, as a single address of the same IP family for each of the given addresses (see
+// ipaddrs for dual-stack addresses).
+//             pools - pools to allocate from, in order of preference
+//             opts - options
+func NewServer(pools []*ipam.Pool, opts ...Option) networkservice.NetworkServiceServer {
+	rv := &ipamServer{
+		pools:       pools,
+		allocations: make(map[string]*allocation),
+	}
+	for _, opt := range opts {
+		opt(rv)
+	}
+	return rv
+}
+
+func (s *ipamServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conn := request.GetConnection()
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetIpContext() == nil {
+		conn.GetContext().IpContext = &networkservice.IPContext{}
+	}
+	ipContext := conn.GetContext().GetIpContext()
+
+	s.mu.Lock()
+	alloc, existed := s.allocations[conn.GetId()]
+	if !existed {
+		var err error
+		if alloc, err = s.allocate(ipContext); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+		s.allocations[conn.GetId()] = alloc
+	}
+	s.mu.Unlock()
+	if alloc.srcIPAddr != "" {
+		ipContext.SrcIpAddr = alloc.srcIPAddr
+	}
+	if alloc.dstIPAddr != "" {
+		ipContext.DstIpAddr = alloc.dstIPAddr
+	}
+
+	rv, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && !existed {
+		s.release(conn.GetId())
+	}
+	return rv, err
+}
+
+func (s *ipamServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	s.release(conn.GetId())
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// allocate reserves the addresses given in ipContext and allocates the missing ones: a pair if both are missing, a
+// single address of the same IP family as each of the given ones otherwise.  Must be called with s.mu held.
+func (s *ipamServer) allocate(ipContext *networkservice.IPContext) (_ *allocation, err error) {
+	var excluded []*net.IPNet
+	for _, prefix := range ipContext.GetExcludedPrefixes() {
+		_, ipNet, parseErr := net.ParseCIDR(prefix)
+		if parseErr != nil {
+			return nil, errors.Wrapf(parseErr, "invalid excluded prefix %q", prefix)
+		}
+		excluded = append(excluded, ipNet)
+	}
+
+	alloc := &allocation{}
+	defer func() {
+		if err != nil {
+			s.free(alloc)
+		}
+	}()
+	if err = s.reserve(alloc, ipContext.GetSrcIpAddr()); err != nil {
+		return nil, err
+	}
+	if err = s.reserve(alloc, ipContext.GetDstIpAddr()); err != nil {
+		return nil, err
+	}
+
+	given := ipContext.GetSrcIpAddr()
+	if given == "" {
+		given = ipContext.GetDstIpAddr()
+	} else if ipContext.GetDstIpAddr() != "" {
+		return alloc, nil
+	}
+	if given == "" {
+		for _, pool := range s.pools {
+			_, bits := pool.Prefix().Mask.Size()
+			if block, allocateErr := pool.Allocate(bits-1, excluded...); allocateErr == nil {
+				alloc.blocks = append(alloc.blocks, block)
+				alloc.srcIPAddr, alloc.dstIPAddr = s.addresses(block)
+				return alloc, nil
+			}
+		}
+		return nil, errors.New("no free address pair left in any pool")
+	}
+
+	var ipAddrs []string
+	for _, givenIPAddr := range ipaddrs.Split(given) {
+		givenIP, _, parseErr := net.ParseCIDR(givenIPAddr)
+		if parseErr != nil {
+			return nil, errors.Wrapf(parseErr, "invalid address %q", givenIPAddr)
+		}
+		block, allocateErr := s.allocateOfFamily(givenIP, excluded)
+		if allocateErr != nil {
+			return nil, allocateErr
+		}
+		if block != nil {
+			alloc.blocks = append(alloc.blocks, block)
+			ipAddrs = append(ipAddrs, block.String())
+		}
+	}
+	if len(ipAddrs) == 0 {
+		return nil, errors.Errorf("no pool of the family of %s", given)
+	}
+	if ipContext.GetSrcIpAddr() == "" {
+		alloc.srcIPAddr = strings.Join(ipAddrs, ",")
+	} else {
+		alloc.dstIPAddr = strings.Join(ipAddrs, ",")
+	}
+	return alloc, nil
+}
+
+// allocateOfFamily allocates a single address of the IP family of ip, nil if there is no pool of that family.  Must
+// be called with s.mu held.
+func (s *ipamServer) allocateOfFamily(ip net.IP, excluded []*net.IPNet) (*net.IPNet, error) {
+	found := false
+	for _, pool := range s.pools {
+		if (len(pool.Prefix().IP) == net.IPv4len) != (ip.To4() != nil) {
+			continue
+		}
+		found = true
+		_, bits := pool.Prefix().Mask.Size()
+		if block, err := pool.Allocate(bits, excluded...); err == nil {
+			return block, nil
+		}
+	}
+	if !found {
+		return nil, nil
+	}
+	return nil, errors.Errorf("no free address of the family of %s left in any pool", ip)
+}
+
+// reserve reserves the addresses of the comma separated list ipAddrs belonging to one of the pools for alloc.  Must
+// be called with s.mu held.
+func (s *ipamServer) reserve(alloc *allocation, ipAddrs string) error {
+	for _, ipAddr := range ipaddrs.Split(ipAddrs) {
+		ip, _, err := net.ParseCIDR(ipAddr)
+		if err != nil {
+			return errors.Wrapf(err, "invalid address %q", ipAddr)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		block := &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}
+		for _, pool := range s.pools {
+			if !pool.Prefix().Contains(block.IP) {
+				continue
+			}
+			if err := pool.Reserve(block); err != nil {
+				return errors.Wrapf(err, "address %s is not available", ipAddr)
+			}
+			alloc.blocks = append(alloc.blocks, block)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *ipamServer) release(connID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	alloc, ok := s.allocations[connID]
+	if !ok {
+		return
+	}
+	delete(s.allocations, connID)
+	s.free(alloc)
+}
+
+// free returns the blocks of alloc to their pools.  Must be called with s.mu held.
+func (s *ipamServer) free(alloc *allocation) {
+	for _, block := range alloc.blocks {
+		for _, pool := range s.pools {
+			if pool.Prefix().Contains(block.IP) {
+				pool.Release(block)
+				break
+			}
+		}
+	}
+}
+
+// addresses returns the src and dst addresses of block in CIDR notation
+func (s *ipamServer) addresses(block *net.IPNet) (srcIPAddr, dstIPAddr string) {
+	srcIP := append(net.IP{}, block.IP...)
+	dstIP := append(net.IP{}, block.IP...)
+	dstIP[len(dstIP)-1] |= 1
+
+	mask := block.Mask
+	if !s.pairMasks {
+		_, bits := block.Mask.Size()
+		mask = net.CIDRMask(bits, bits)
+	}
+	return (&net.IPNet{IP: srcIP, Mask: mask}).String(), (&net.IPNet{IP: dstIP, Mask: mask}).String()
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/ipam"
+	ipampool "github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipam"
+)
+
+func newPool(t *testing.T, prefix string) *ipampool.Pool {
+	pool, err := ipampool.NewPool(prefix)
+	require.NoError(t, err)
+	return pool
+}
+
+func TestIPAMServer(t *testing.T) {
+	server := next.NewNetworkServiceServer(ipam.NewServer([]*ipampool.Pool{newPool(t, "10.0.0.0/30"), newPool(t, "fd00::/126")}))
+
+	conn1, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/32", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "10.0.0.1/32", conn1.GetContext().GetIpContext().GetDstIpAddr())
+
+	// Refreshes get the same addresses
+	conn1, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn1})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/32", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+
+	// Excluded prefixes are respected, falling back to the next pool
+	conn2, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "2",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					ExcludedPrefixes: []string{"10.0.0.2/32", "fd00::/127"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fd00::2/128", conn2.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "fd00::3/128", conn2.GetContext().GetIpContext().GetDstIpAddr())
+
+	// Released addresses are handed out again
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	conn3, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "3"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/32", conn3.GetContext().GetIpContext().GetSrcIpAddr())
+}
+
+func TestIPAMServer_PairMasks(t *testing.T) {
+	server := next.NewNetworkServiceServer(ipam.NewServer([]*ipampool.Pool{newPool(t, "10.0.0.0/24")}, ipam.WithPairMasks()))
+	conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/31", conn.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "10.0.0.1/31", conn.GetContext().GetIpContext().GetDstIpAddr())
+}
+
+func TestIPAMServer_Exhausted(t *testing.T) {
+	server := next.NewNetworkServiceServer(ipam.NewServer([]*ipampool.Pool{newPool(t, "10.0.0.0/31")}))
+	_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "2"},
+	})
+	assert.Error(t, err)
+}
+
+func TestIPAMServer_OneAddressGiven(t *testing.T) {
+	server := next.NewNetworkServiceServer(ipam.NewServer([]*ipampool.Pool{newPool(t, "fd00::/126"), newPool(t, "10.0.0.0/30")}))
+
+	// The given dst address is kept and a src address of the same family, other than the given one, is allocated
+	conn1, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					DstIpAddr: "10.0.0.0/32",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1/32", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "10.0.0.0/32", conn1.GetContext().GetIpContext().GetDstIpAddr())
+
+	// Refreshes get the same addresses
+	conn1, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn1})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1/32", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "10.0.0.0/32", conn1.GetContext().GetIpContext().GetDstIpAddr())
+
+	// The same for a given src address
+	conn2, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "2",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "fd00::/128",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fd00::/128", conn2.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "fd00::1/128", conn2.GetContext().GetIpContext().GetDstIpAddr())
+
+	// Invalid given addresses are rejected
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "3",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "10.0.0.1",
+				},
+			},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestIPAMServer_GivenAddressesReserved(t *testing.T) {
+	server := next.NewNetworkServiceServer(ipam.NewServer([]*ipampool.Pool{newPool(t, "10.0.0.0/29")}))
+	request := func(id, srcIPAddr, dstIPAddr string) *networkservice.NetworkServiceRequest {
+		return &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id: id,
+				Context: &networkservice.ConnectionContext{
+					IpContext: &networkservice.IPContext{
+						SrcIpAddr: srcIPAddr,
+						DstIpAddr: dstIPAddr,
+					},
+				},
+			},
+		}
+	}
+
+	// Given addresses are never handed out to other connections
+	conn1, err := server.Request(context.Background(), request("1", "10.0.0.0/32", "10.0.0.2/32"))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/32", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "10.0.0.2/32", conn1.GetContext().GetIpContext().GetDstIpAddr())
+	conn2, err := server.Request(context.Background(), request("2", "", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.4/32", conn2.GetContext().GetIpContext().GetSrcIpAddr())
+
+	// Given addresses in use are rejected, addresses outside of the pools are accepted
+	_, err = server.Request(context.Background(), request("3", "10.0.0.2/32", ""))
+	assert.Error(t, err)
+	_, err = server.Request(context.Background(), request("3", "10.0.1.0/32", "10.0.0.5/32"))
+	assert.Error(t, err)
+	conn3, err := server.Request(context.Background(), request("3", "10.0.1.0/32", "10.0.1.1/32"))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.1.0/32", conn3.GetContext().GetIpContext().GetSrcIpAddr())
+
+	// Closing the connection frees its given addresses
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	conn4, err := server.Request(context.Background(), request("4", "", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/32", conn4.GetContext().GetIpContext().GetSrcIpAddr())
+}
+
+func TestIPAMServer_DualStack(t *testing.T) {
+	server := next.NewNetworkServiceServer(ipam.NewServer([]*ipampool.Pool{newPool(t, "10.0.0.0/30"), newPool(t, "fd00::/126")}))
+
+	// A dst address is allocated for each family of the given dual-stack src address, both of which are reserved
+	conn1, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "10.0.0.0/32,fd00::/128",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/32,fd00::/128", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "10.0.0.1/32,fd00::1/128", conn1.GetContext().GetIpContext().GetDstIpAddr())
+
+	// Both given dual-stack addresses are reserved
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "2",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "10.0.0.2/32,fd00::1/128",
+					DstIpAddr: "10.0.0.3/32,fd00::3/128",
+				},
+			},
+		},
+	})
+	assert.Error(t, err)
+	conn2, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "2",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: "10.0.0.2/32,fd00::2/128",
+					DstIpAddr: "10.0.0.3/32,fd00::3/128",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2/32,fd00::2/128", conn2.GetContext().GetIpContext().GetSrcIpAddr())
+
+	// Families without a pool are skipped
+	server = next.NewNetworkServiceServer(ipam.NewServer([]*ipampool.Pool{newPool(t, "10.0.0.0/30")}))
+	conn3, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "3",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					DstIpAddr: "10.0.0.0/32,fd00::/128",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1/32", conn3.GetContext().GetIpContext().GetSrcIpAddr())
+}
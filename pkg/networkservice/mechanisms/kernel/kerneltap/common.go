@@ -14,15 +14,15 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// +build !windows
+
 // Package kerneltap provides networkservice chain elements that support the kernel Mechanism via tapv2
 package kerneltap
 
 import (
 	"context"
-	"net/url"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
-	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 
 	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
@@ -33,28 +33,29 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifname"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netns"
 )
 
 const (
-	fileScheme = "file"
+	// podIfPrefix - prefix of the names generated for pod side interfaces
+	podIfPrefix = "nsm"
 )
 
-func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, name string) error {
+// appendInterfaceConfig appends the interfaces of the kernel mechanism of conn in the network namespace netNS, which
+// the caller resolves on Request and reuses on Close (see netns.Namespaces)
+func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, name string, names *ifname.Names, netNS *linuxnamespace.NetNamespace) {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		netNSURLStr := mechanism.GetNetNSURL()
-		netNSURL, err := url.Parse(netNSURLStr)
-		if err != nil {
-			return err
-		}
-		if netNSURL.Scheme != fileScheme {
-			return errors.Errorf("kernel.ToMechanism(conn.GetMechanism()).GetNetNSURL() must be of scheme %q: %q", fileScheme, netNSURL)
+		ifaceName := names.Get(conn.GetId(), mechanism.GetInterfaceName(conn), conn.GetMechanism().GetParameters()[ifname.NameKey], netns.Filename(netNS))
+		if conn.GetMechanism().GetParameters() == nil {
+			conn.GetMechanism().Parameters = make(map[string]string)
 		}
-		vppagentConfigTemplate(vppagent.Config(ctx), name, kernel.ToMechanism(conn.GetMechanism()).GetInterfaceName(conn), netNSURL.Path)
+		conn.GetMechanism().GetParameters()[ifname.NameKey] = ifaceName
+		vppagentConfigTemplate(vppagent.Config(ctx), name, ifaceName, netNS)
 	}
-	return nil
 }
 
-func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFilename string) {
+func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName string, netNS *linuxnamespace.NetNamespace) {
 	// We append an Interfaces.  Interfaces creates the vpp side of an interface.
 	//   In this case, a Tapv2 interface that has one side in vpp, and the other
 	//   as a Linux kernel interface
@@ -70,16 +71,13 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 	})
 	// We apply configuration to LinuxInterfaces
 	// Important details:
-	//    - LinuxInterfaces.HostIfName - must be no longer than 15 chars (linux limitation)
+	//    - LinuxInterfaces.HostIfName - must be no longer than 15 chars (linux limitation), which ifname.Names ensures
 	conf.GetLinuxConfig().Interfaces = append(conf.GetLinuxConfig().Interfaces, &linux.Interface{
 		Name:       name,
 		Type:       linuxinterfaces.Interface_TAP_TO_VPP,
 		Enabled:    true,
-		HostIfName: linuxIfaceName(ifaceName),
-		Namespace: &linuxnamespace.NetNamespace{
-			Type:      linuxnamespace.NetNamespace_FD,
-			Reference: netnsFilename,
-		},
+		HostIfName: ifaceName,
+		Namespace:  netNS,
 		Link: &linuxinterfaces.Interface_Tap{
 			Tap: &linuxinterfaces.TapLink{
 				VppTapIfName: name,
@@ -87,10 +85,3 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		},
 	})
 }
-
-func linuxIfaceName(ifaceName string) string {
-	if len(ifaceName) <= kernel.LinuxIfMaxLength {
-		return ifaceName
-	}
-	return ifaceName[:kernel.LinuxIfMaxLength]
-}
@@ -14,14 +14,22 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// +build !windows
+
 package kerneltap_test
 
 import (
+	"context"
 	"io/ioutil"
 	"net/url"
 	"testing"
 
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
@@ -30,6 +38,9 @@ import (
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/checkvppagentmechanism"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mechanisms/kernel/kerneltap"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifname"
 )
 
 func TestKernelTapServer(t *testing.T) {
@@ -63,3 +74,97 @@ func TestKernelTapServer(t *testing.T) {
 		testConnToClose,
 	))
 }
+
+type failingServer struct {
+	fail bool
+}
+
+func (f *failingServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	if f.fail {
+		return nil, errors.New("failure")
+	}
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (f *failingServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func kernelRequest(ifaceName string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.LOCAL,
+				Type: kernel.MECHANISM,
+				Parameters: map[string]string{
+					kernel.NetNSURL:         (&url.URL{Scheme: "file", Path: netnsFileURL}).String(),
+					kernel.InterfaceNameKey: ifaceName,
+				},
+			},
+		},
+	}
+}
+
+func TestKernelTapServer_Names(t *testing.T) {
+	failing := &failingServer{fail: true}
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		kerneltap.NewServer(),
+		capture,
+		failing,
+	)
+
+	// The name chosen by a failed first Request is not kept
+	_, err := server.Request(context.Background(), kernelRequest("nsm0"))
+	require.Error(t, err)
+	failing.fail = false
+	conn, err := server.Request(context.Background(), kernelRequest("nsm1"))
+	require.NoError(t, err)
+	assert.Equal(t, "nsm1", capture.Config().GetLinuxConfig().GetInterfaces()[0].GetHostIfName())
+	assert.Equal(t, "nsm1", conn.GetMechanism().GetParameters()[ifname.NameKey])
+
+	// After a restart the name recorded in the connection is kept
+	server = next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		kerneltap.NewServer(),
+		capture,
+	)
+	conn.GetMechanism().GetParameters()[kernel.InterfaceNameKey] = "nsm2"
+	_, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	assert.Equal(t, "nsm1", capture.Config().GetLinuxConfig().GetInterfaces()[0].GetHostIfName())
+}
+
+func TestKernelTapServer_CloseUnresolvable(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		kerneltap.NewServer(),
+		capture,
+	)
+	request := kernelRequest("nsm0")
+	request.GetConnection().GetMechanism().GetParameters()[kernel.NetNSURL] = "netns://red"
+	conn, err := server.Request(context.Background(), request)
+	require.NoError(t, err)
+
+	// The Close of a connection whose network namespace is gone deletes the interface in the network namespace
+	// resolved by the Request
+	conn.GetMechanism().GetParameters()[kernel.NetNSURL] = "inode://not-a-number"
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetLinuxConfig().GetInterfaces(), 1)
+	assert.Equal(t, "red", capture.Config().GetLinuxConfig().GetInterfaces()[0].GetNamespace().GetReference())
+
+	// So does a Close without a Request after a restart, without the network namespace
+	server = next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		kerneltap.NewServer(),
+		capture,
+	)
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetLinuxConfig().GetInterfaces(), 1)
+	assert.Equal(t, "nsm0", capture.Config().GetLinuxConfig().GetInterfaces()[0].GetHostIfName())
+}
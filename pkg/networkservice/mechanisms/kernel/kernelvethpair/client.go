@@ -14,6 +14,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// +build !windows
+
 package kernelvethpair
 
 import (
@@ -28,13 +30,24 @@ import (
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
 
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifname"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netns"
 )
 
-type kernelVethPairClient struct{}
+type kernelVethPairClient struct {
+	names      *ifname.Names
+	namespaces *netns.Namespaces
+}
 
 // NewClient provides NetworkServiceClient chain elements that support the kernel Mechanism using veth pairs
 func NewClient() networkservice.NetworkServiceClient {
-	return &kernelVethPairClient{}
+	return &kernelVethPairClient{
+		names:      ifname.NewNames(podIfPrefix),
+		namespaces: netns.NewNamespaces(),
+	}
 }
 
 func (k *kernelVethPairClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
@@ -47,9 +60,14 @@ func (k *kernelVethPairClient) Request(ctx context.Context, request *networkserv
 	if err != nil {
 		return nil, err
 	}
-	if err := appendInterfaceConfig(ctx, conn, "client"); err != nil {
-		return nil, err
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
+		netNS, err := k.namespaces.Resolve(conn.GetId(), mechanism.GetNetNSURL())
+		if err != nil {
+			return nil, err
+		}
+		appendInterfaceConfig(ctx, conn, "client", k.names, netNS)
 	}
+	setClientInterface(ctx, conn)
 	return conn, nil
 }
 
@@ -58,9 +76,21 @@ func (k *kernelVethPairClient) Close(ctx context.Context, conn *networkservice.C
 	if err != nil {
 		return nil, err
 	}
-	err = appendInterfaceConfig(ctx, conn, "client")
-	if err != nil {
-		return nil, err
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
+		appendInterfaceConfig(ctx, conn, "client", k.names, k.namespaces.Load(conn.GetId(), mechanism.GetNetNSURL()))
+	}
+	setClientInterface(ctx, conn)
+	k.names.Delete(conn.GetId())
+	k.namespaces.Delete(conn.GetId())
+	return rv, nil
+}
+
+// setClientInterface hands the pod side interface to the connection context client elements preceding this one
+func setClientInterface(ctx context.Context, conn *networkservice.Connection) {
+	if kernel.ToMechanism(conn.GetMechanism()) == nil {
+		return
+	}
+	if linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces(); len(linuxIfaces) > 0 {
+		kernelctx.SetClientInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
 	}
-	return rv, err
 }
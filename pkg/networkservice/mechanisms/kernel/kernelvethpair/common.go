@@ -14,50 +14,51 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// +build !windows
+
 package kernelvethpair
 
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/networkservicemesh/api/pkg/api/networkservice"
 	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
-	"github.com/pkg/errors"
 	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
 	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
 	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
 	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifname"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netns"
 )
 
 const (
-	fileScheme = "file"
+	// podIfPrefix - prefix of the names generated for pod side interfaces
+	podIfPrefix = "nsm"
 )
 
-func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, prefix string) error {
+// appendInterfaceConfig appends the interfaces of the kernel mechanism of conn in the network namespace netNS, which
+// the caller resolves on Request and reuses on Close (see netns.Namespaces)
+func appendInterfaceConfig(ctx context.Context, conn *networkservice.Connection, prefix string, names *ifname.Names, netNS *linuxnamespace.NetNamespace) {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		netNSURLStr := mechanism.GetNetNSURL()
-		netNSURL, err := url.Parse(netNSURLStr)
-		if err != nil {
-			return err
-		}
-		if netNSURL.Scheme != fileScheme {
-			return errors.Errorf("kernel.ToMechanism(conn.GetMechanism()).GetNetNSURL() must be of scheme %q: %q", fileScheme, netNSURL)
+		ifaceName := names.Get(conn.GetId(), mechanism.GetInterfaceName(conn), conn.GetMechanism().GetParameters()[ifname.NameKey], netns.Filename(netNS))
+		if conn.GetMechanism().GetParameters() == nil {
+			conn.GetMechanism().Parameters = make(map[string]string)
 		}
-		vppagentConfigTemplate(vppagent.Config(ctx), fmt.Sprintf("%s-%s", prefix, conn.GetId()), kernel.ToMechanism(conn.GetMechanism()).GetInterfaceName(conn), netNSURL.Path)
+		conn.GetMechanism().GetParameters()[ifname.NameKey] = ifaceName
+		vppagentConfigTemplate(vppagent.Config(ctx), fmt.Sprintf("%s-%s", prefix, conn.GetId()), ifname.Generate(prefix, conn.GetId()), ifaceName, netNS)
 	}
-	return nil
 }
 
-func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFilename string) {
+func vppagentConfigTemplate(conf *configurator.Config, name, hostIfaceName, ifaceName string, netNS *linuxnamespace.NetNamespace) {
 	conf.GetLinuxConfig().Interfaces = append(conf.GetLinuxConfig().Interfaces,
 		&linuxinterfaces.Interface{
 			Name:       name + "-veth",
 			Type:       linuxinterfaces.Interface_VETH,
 			Enabled:    true,
-			HostIfName: linuxIfaceName(name),
+			HostIfName: hostIfaceName,
 			Link: &linuxinterfaces.Interface_Veth{
 				Veth: &linuxinterfaces.VethLink{
 					PeerIfName:           name,
@@ -70,11 +71,8 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 			Name:       name,
 			Type:       linuxinterfaces.Interface_VETH,
 			Enabled:    true,
-			HostIfName: linuxIfaceName(ifaceName),
-			Namespace: &linuxnamespace.NetNamespace{
-				Type:      linuxnamespace.NetNamespace_FD,
-				Reference: netnsFilename,
-			},
+			HostIfName: ifaceName,
+			Namespace:  netNS,
 			Link: &linuxinterfaces.Interface_Veth{
 				Veth: &linuxinterfaces.VethLink{
 					PeerIfName:           name + "-veth",
@@ -89,15 +87,8 @@ func vppagentConfigTemplate(conf *configurator.Config, name, ifaceName, netnsFil
 		Enabled: true,
 		Link: &vppinterfaces.Interface_Afpacket{
 			Afpacket: &vppinterfaces.AfpacketLink{
-				HostIfName: linuxIfaceName(name),
+				HostIfName: hostIfaceName,
 			},
 		},
 	})
 }
-
-func linuxIfaceName(ifaceName string) string {
-	if len(ifaceName) <= kernel.LinuxIfMaxLength {
-		return ifaceName
-	}
-	return ifaceName[:kernel.LinuxIfMaxLength]
-}
@@ -14,6 +14,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// +build !windows
+
 // Package kernelvethpair provides networkservice chain elements that support the kernel Mechanism using veth pairs
 package kernelvethpair
 
@@ -28,34 +30,51 @@ import (
 	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
 
 	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifname"
 	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netns"
 )
 
-type kernelVethPairServer struct{}
+type kernelVethPairServer struct {
+	names      *ifname.Names
+	namespaces *netns.Namespaces
+}
 
 // NewServer provides NetworkServiceServer chain elements that support the kernel Mechanism using veth pairs
 func NewServer() networkservice.NetworkServiceServer {
-	return &kernelVethPairServer{}
+	return &kernelVethPairServer{
+		names:      ifname.NewNames(podIfPrefix),
+		namespaces: netns.NewNamespaces(),
+	}
 }
 
 func (k *kernelVethPairServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
-	if mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism()); mechanism != nil {
-		err := appendInterfaceConfig(ctx, request.GetConnection(), "server")
-		if err != nil {
-			return nil, err
-		}
-		linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
-		ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+	mechanism := kernel.ToMechanism(request.GetConnection().GetMechanism())
+	if mechanism == nil {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	existed := k.names.Has(request.GetConnection().GetId())
+	netNS, err := k.namespaces.Resolve(request.GetConnection().GetId(), mechanism.GetNetNSURL())
+	if err != nil {
+		return nil, err
+	}
+	appendInterfaceConfig(ctx, request.GetConnection(), "server", k.names, netNS)
+	linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
+	ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && !existed {
+		// The connection was not established, so free its name for others
+		k.names.Delete(request.GetConnection().GetId())
+		k.namespaces.Delete(request.GetConnection().GetId())
 	}
-	return next.Server(ctx).Request(ctx, request)
+	return conn, err
 }
 
 func (k *kernelVethPairServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
 	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
-		err := appendInterfaceConfig(ctx, conn, "server")
-		if err != nil {
-			return nil, err
-		}
+		appendInterfaceConfig(ctx, conn, "server", k.names, k.namespaces.Load(conn.GetId(), mechanism.GetNetNSURL()))
+		k.names.Delete(conn.GetId())
+		k.namespaces.Delete(conn.GetId())
 		linuxIfaces := vppagent.Config(ctx).GetLinuxConfig().GetInterfaces()
 		ctx = kernelctx.WithServerInterface(ctx, linuxIfaces[len(linuxIfaces)-1])
 	}
@@ -0,0 +1,63 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mtu
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+type mtuClient struct {
+	underlayMTU uint32
+}
+
+// NewClient creates a NetworkServiceClient chain element that lowers the MTU of outgoing connections to what
+// underlayMTU allows for the selected mechanism and sets it on their vpp and kernel interfaces
+// When used for the outgoing connection of a passthrough chain with mtu.NewServer, it lowers the MTU of the
+// interfaces of the incoming connection as well.
+//             underlayMTU - MTU of the network carrying remote mechanisms
+func NewClient(underlayMTU uint32) networkservice.NetworkServiceClient {
+	return &mtuClient{
+		underlayMTU: underlayMTU,
+	}
+}
+
+func (m *mtuClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	ctx = kernelctx.WithClientInterface(ctx)
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if mtu := lowerMTU(conn, m.underlayMTU); mtu != 0 {
+		setClientMTU(ctx, conn, mtu)
+		if ifaces := getServerInterfaces(ctx); ifaces != nil {
+			ifaces.setMTU(mtu)
+		}
+	}
+	return conn, nil
+}
+
+func (m *mtuClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mtu provides networkservice chain elements that compute the MTU of connections and apply it to their
+// interfaces
+// The MTU of a connection is the underlay MTU less the encapsulation overhead of the connection's mechanism.  It is
+// kept in the ExtraContext of the connection under MTUKey, and every element lowers it as needed, so that the MTU
+// of a connection passing through several vWires is the smallest MTU of all of them.
+// In a passthrough chain, the server element stores the interfaces of the incoming connection in the context, so that
+// the client element can lower their MTU to that of the outgoing connection.
+package mtu
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/srv6"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+const (
+	// MTUKey - key of the MTU in the ExtraContext of a connection
+	MTUKey = "mtu"
+	// vxlanOverhead - outer IPv4 (20) + UDP (8) + VXLAN (8) headers and the inner Ethernet header (14)
+	vxlanOverhead = 50
+	// srv6Overhead - outer IPv6 (40) + SRH with a single segment (24) headers and the inner Ethernet header (14)
+	srv6Overhead = 78
+)
+
+// Overhead - returns the encapsulation overhead of mechanism in bytes
+func Overhead(mechanism *networkservice.Mechanism) uint32 {
+	switch mechanism.GetType() {
+	case vxlan.MECHANISM:
+		return vxlanOverhead
+	case srv6.MECHANISM:
+		return srv6Overhead
+	default:
+		return 0
+	}
+}
+
+// lowerMTU lowers the MTU of conn to what the underlay allows for the mechanism of conn and returns it.  Returns 0 if
+// the MTU is unknown.
+func lowerMTU(conn *networkservice.Connection, underlayMTU uint32) uint32 {
+	mtu := uint32(0)
+	if value, err := strconv.ParseUint(conn.GetContext().GetExtraContext()[MTUKey], 10, 32); err == nil {
+		mtu = uint32(value)
+	}
+	if underlayMTU > Overhead(conn.GetMechanism()) {
+		if local := underlayMTU - Overhead(conn.GetMechanism()); mtu == 0 || local < mtu {
+			mtu = local
+		}
+	}
+	if mtu == 0 {
+		return 0
+	}
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetExtraContext() == nil {
+		conn.GetContext().ExtraContext = make(map[string]string)
+	}
+	conn.GetContext().GetExtraContext()[MTUKey] = strconv.FormatUint(uint64(mtu), 10)
+	return mtu
+}
+
+type contextKeyType string
+
+const serverInterfacesKey contextKeyType = "mtuServerInterfaces"
+
+// serverInterfaces - the interfaces of an incoming connection
+type serverInterfaces struct {
+	vppInterface    *vpp.Interface
+	kernelInterface *linux.Interface
+}
+
+// setMTU sets mtu on the interfaces
+func (s *serverInterfaces) setMTU(mtu uint32) {
+	if s.vppInterface != nil {
+		s.vppInterface.Mtu = mtu
+	}
+	if s.kernelInterface != nil {
+		s.kernelInterface.Mtu = mtu
+	}
+}
+
+// withServerInterfaces returns ctx with the interfaces of the incoming connection stored in it
+func withServerInterfaces(ctx context.Context) (context.Context, *serverInterfaces) {
+	rv := &serverInterfaces{
+		kernelInterface: kernelctx.ServerInterface(ctx),
+	}
+	rv.vppInterface = connectionVppInterface(vppagent.Config(ctx).GetVppConfig(), rv.kernelInterface)
+	return context.WithValue(ctx, serverInterfacesKey, rv), rv
+}
+
+// getServerInterfaces returns the interfaces stored in ctx by withServerInterfaces, or nil
+func getServerInterfaces(ctx context.Context) *serverInterfaces {
+	if rv, ok := ctx.Value(serverInterfacesKey).(*serverInterfaces); ok {
+		return rv
+	}
+	return nil
+}
+
+// setClientMTU sets mtu on the interfaces of the outgoing connection
+func setClientMTU(ctx context.Context, conn *networkservice.Connection, mtu uint32) {
+	var kernelInterface *linux.Interface
+	if mechanism := kernel.ToMechanism(conn.GetMechanism()); mechanism != nil {
+		kernelInterface = kernelctx.ClientInterface(ctx)
+	}
+	ifaces := &serverInterfaces{
+		vppInterface:    connectionVppInterface(vppagent.Config(ctx).GetVppConfig(), kernelInterface),
+		kernelInterface: kernelInterface,
+	}
+	ifaces.setMTU(mtu)
+}
+
+// connectionVppInterface returns the vpp interface of a connection: the vpp side of kernelInterface for kernel
+// mechanisms, the last vpp interface which is not a loopback otherwise.  Loopbacks are skipped because they are never
+// the interface of a connection, but the shared BVI or gateway added by elements like bridge or vl3.
+func connectionVppInterface(vppConfig *vpp.ConfigData, kernelInterface *linux.Interface) *vpp.Interface {
+	if kernelInterface != nil {
+		return kernelctx.VppInterface(vppConfig, kernelInterface)
+	}
+	ifaces := vppConfig.GetInterfaces()
+	for i := len(ifaces) - 1; i >= 0; i-- {
+		if ifaces[i].GetType() != vppinterfaces.Interface_SOFTWARE_LOOPBACK {
+			return ifaces[i]
+		}
+	}
+	return nil
+}
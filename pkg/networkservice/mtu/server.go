@@ -0,0 +1,54 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mtu
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+)
+
+type mtuServer struct {
+	underlayMTU uint32
+}
+
+// NewServer creates a NetworkServiceServer chain element that lowers the MTU of incoming connections to what
+// underlayMTU allows for their mechanism and sets it on their vpp and kernel interfaces
+// It must be placed after the mechanism chain elements.  The vpp interface of the connection is the vpp side of the
+// kernel interface (see kernelctx) for kernel mechanisms, the last vpp interface in the config which is not a loopback
+// otherwise.
+//             underlayMTU - MTU of the network carrying remote mechanisms
+func NewServer(underlayMTU uint32) networkservice.NetworkServiceServer {
+	return &mtuServer{
+		underlayMTU: underlayMTU,
+	}
+}
+
+func (m *mtuServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	ctx, ifaces := withServerInterfaces(ctx)
+	if mtu := lowerMTU(request.GetConnection(), m.underlayMTU); mtu != 0 {
+		ifaces.setMTU(mtu)
+	}
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (m *mtuServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mtu_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/adapters"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/cls"
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/vxlan"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/bridge"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/mtu"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testvppagentcc"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+// vxlanSelector plays the part of an Endpoint selecting the vxlan mechanism for the outgoing connection
+type vxlanSelector struct{}
+
+func (v *vxlanSelector) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn := request.GetConnection().Clone()
+	conn.Mechanism = &networkservice.Mechanism{
+		Cls:  cls.REMOTE,
+		Type: vxlan.MECHANISM,
+	}
+	return conn, nil
+}
+
+func (v *vxlanSelector) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return &empty.Empty{}, nil
+}
+
+func TestMTUServer(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		mtu.NewServer(1500),
+		capture,
+	)
+	conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.REMOTE,
+				Type: vxlan.MECHANISM,
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1450", conn.GetContext().GetExtraContext()[mtu.MTUKey])
+	require.Len(t, capture.Config().GetVppConfig().GetInterfaces(), 1)
+	assert.Equal(t, uint32(1450), capture.Config().GetVppConfig().GetInterfaces()[0].GetMtu())
+}
+
+func TestMTUServer_SharedLoopback(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		bridge.NewServer(testvppagentcc.New(), "test-bridge", bridge.WithBVI("10.0.0.1/24")),
+		mtu.NewServer(1500),
+		capture,
+	)
+	_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Mechanism: &networkservice.Mechanism{
+				Cls:  cls.REMOTE,
+				Type: vxlan.MECHANISM,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// The MTU is set on the interface of the connection, not on the BVI shared by all connections
+	ifaces := capture.Config().GetVppConfig().GetInterfaces()
+	require.Len(t, ifaces, 2)
+	assert.Equal(t, uint32(1450), ifaces[0].GetMtu())
+	assert.Equal(t, "test-bridge-bvi", ifaces[1].GetName())
+	assert.Equal(t, uint32(0), ifaces[1].GetMtu())
+}
+
+func TestMTUServer_LowerUpstreamMTU(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		mtu.NewServer(9000),
+		capture,
+	)
+	conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Context: &networkservice.ConnectionContext{
+				ExtraContext: map[string]string{mtu.MTUKey: "1400"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1400", conn.GetContext().GetExtraContext()[mtu.MTUKey])
+	assert.Equal(t, uint32(1400), capture.Config().GetVppConfig().GetInterfaces()[0].GetMtu())
+}
+
+func TestMTUServerClient_Passthrough(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		mtu.NewServer(1500),
+		capture,
+		adapters.NewClientToServer(next.NewNetworkServiceClient(
+			mtu.NewClient(1500),
+			testinterfaceappender.NewClient(),
+			&vxlanSelector{},
+		)),
+	)
+	conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1450", conn.GetContext().GetExtraContext()[mtu.MTUKey])
+	require.Len(t, capture.Config().GetVppConfig().GetInterfaces(), 2)
+	assert.Equal(t, uint32(1450), capture.Config().GetVppConfig().GetInterfaces()[0].GetMtu())
+	assert.Equal(t, uint32(1450), capture.Config().GetVppConfig().GetInterfaces()[1].GetMtu())
+}
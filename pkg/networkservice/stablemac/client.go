@@ -0,0 +1,78 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablemac
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+type stableMacClient struct{}
+
+// NewClient creates a NetworkServiceClient chain element that sets deterministic mac addresses on the interfaces
+// leaving the Client
+//  Missing SrcMac and DstMac of the EthernetContext are derived from the connection id before the Request is sent.  A
+//  kernel interface gets the SrcMac and its vpp side the DstMac, a vpp only interface gets the SrcMac.
+func NewClient() networkservice.NetworkServiceClient {
+	return &stableMacClient{}
+}
+
+func (c *stableMacClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	setEthernetContext(request.GetConnection())
+	ctx = kernelctx.WithClientInterface(ctx)
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	setEthernetContext(conn)
+	c.setPhysAddresses(ctx, conn)
+	return conn, nil
+}
+
+func (c *stableMacClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	setEthernetContext(conn)
+	ctx = kernelctx.WithClientInterface(ctx)
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.setPhysAddresses(ctx, conn)
+	return rv, nil
+}
+
+func (c *stableMacClient) setPhysAddresses(ctx context.Context, conn *networkservice.Connection) {
+	ethernetContext := conn.GetContext().GetEthernetContext()
+	linuxIface := kernelctx.ClientInterface(ctx)
+	vppIface := connectionVppInterface(vppagent.Config(ctx).GetVppConfig(), linuxIface)
+	if linuxIface != nil {
+		linuxIface.PhysAddress = ethernetContext.GetSrcMac()
+		if vppIface != nil {
+			vppIface.PhysAddress = ethernetContext.GetDstMac()
+		}
+		return
+	}
+	if vppIface != nil {
+		vppIface.PhysAddress = ethernetContext.GetSrcMac()
+	}
+}
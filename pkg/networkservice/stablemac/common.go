@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stablemac provides networkservice chain elements that assign deterministic mac addresses to the interfaces
+// of a connection, so they survive heals and do not invalidate the neighbor caches of the peers
+package stablemac
+
+import (
+	"crypto/sha256"
+	"net"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+const (
+	srcSide = "src"
+	dstSide = "dst"
+)
+
+// Generate returns a locally administered unicast mac address derived from the connection id and its side
+func Generate(id, side string) string {
+	sum := sha256.Sum256([]byte(id + "/" + side))
+	mac := net.HardwareAddr(sum[:6])
+	mac[0] = (mac[0] | 0x02) &^ 0x01
+	return mac.String()
+}
+
+// setEthernetContext fills in the SrcMac and DstMac of conn that are not set yet
+func setEthernetContext(conn *networkservice.Connection) {
+	if conn == nil {
+		return
+	}
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetEthernetContext() == nil {
+		conn.GetContext().EthernetContext = &networkservice.EthernetContext{}
+	}
+	ethernetContext := conn.GetContext().GetEthernetContext()
+	if ethernetContext.GetSrcMac() == "" {
+		ethernetContext.SrcMac = Generate(conn.GetId(), srcSide)
+	}
+	if ethernetContext.GetDstMac() == "" {
+		ethernetContext.DstMac = Generate(conn.GetId(), dstSide)
+	}
+}
+
+// connectionVppInterface returns the vpp interface of a connection: the vpp side of kernelInterface for kernel
+// mechanisms, the last vpp interface which is not a loopback otherwise.  Loopbacks are skipped because they are never
+// the interface of a connection, but the shared BVI or gateway added by elements like bridge or vl3.
+func connectionVppInterface(vppConfig *vpp.ConfigData, kernelInterface *linux.Interface) *vpp.Interface {
+	if kernelInterface != nil {
+		return kernelctx.VppInterface(vppConfig, kernelInterface)
+	}
+	ifaces := vppConfig.GetInterfaces()
+	for i := len(ifaces) - 1; i >= 0; i-- {
+		if ifaces[i].GetType() != vppinterfaces.Interface_SOFTWARE_LOOPBACK {
+			return ifaces[i]
+		}
+	}
+	return nil
+}
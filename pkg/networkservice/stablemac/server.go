@@ -0,0 +1,67 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablemac
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+type stableMacServer struct{}
+
+// NewServer creates a NetworkServiceServer chain element that sets deterministic mac addresses on the interfaces
+// plugged into the Endpoint
+//  Missing SrcMac and DstMac of the EthernetContext are derived from the connection id.  A kernel interface gets the
+//  DstMac and its vpp side the SrcMac, a vpp only interface gets the DstMac.  It must follow the mechanism elements in
+//  the chain.
+func NewServer() networkservice.NetworkServiceServer {
+	return &stableMacServer{}
+}
+
+func (s *stableMacServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	setEthernetContext(request.GetConnection())
+	s.setPhysAddresses(ctx, request.GetConnection())
+	return next.Server(ctx).Request(ctx, request)
+}
+
+func (s *stableMacServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	setEthernetContext(conn)
+	s.setPhysAddresses(ctx, conn)
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func (s *stableMacServer) setPhysAddresses(ctx context.Context, conn *networkservice.Connection) {
+	ethernetContext := conn.GetContext().GetEthernetContext()
+	linuxIface := kernelctx.ServerInterface(ctx)
+	vppIface := connectionVppInterface(vppagent.Config(ctx).GetVppConfig(), linuxIface)
+	if linuxIface != nil {
+		linuxIface.PhysAddress = ethernetContext.GetDstMac()
+		if vppIface != nil {
+			vppIface.PhysAddress = ethernetContext.GetSrcMac()
+		}
+		return
+	}
+	if vppIface != nil {
+		vppIface.PhysAddress = ethernetContext.GetDstMac()
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stablemac_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/linux"
+	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/bridge"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/stablemac"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testvppagentcc"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/kernelctx"
+)
+
+func TestGenerate(t *testing.T) {
+	mac, err := net.ParseMAC(stablemac.Generate("1", "src"))
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x02), mac[0]&0x03, "expected a locally administered unicast mac address")
+	assert.Equal(t, stablemac.Generate("1", "src"), stablemac.Generate("1", "src"))
+	assert.NotEqual(t, stablemac.Generate("1", "src"), stablemac.Generate("1", "dst"))
+	assert.NotEqual(t, stablemac.Generate("1", "src"), stablemac.Generate("2", "src"))
+}
+
+func TestServer(t *testing.T) {
+	for _, kernelMechanism := range []bool{true, false} {
+		mechanism := &interfaceServer{kernel: kernelMechanism}
+		server := next.NewNetworkServiceServer(vppagent.NewServer(), mechanism, stablemac.NewServer())
+		conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{Id: "1"},
+		})
+		require.NoError(t, err)
+		srcMac := stablemac.Generate("1", "src")
+		dstMac := stablemac.Generate("1", "dst")
+		assert.Equal(t, srcMac, conn.GetContext().GetEthernetContext().GetSrcMac())
+		assert.Equal(t, dstMac, conn.GetContext().GetEthernetContext().GetDstMac())
+		if kernelMechanism {
+			assert.Equal(t, dstMac, mechanism.linuxIface.GetPhysAddress())
+			assert.Equal(t, srcMac, mechanism.vppIface.GetPhysAddress())
+		} else {
+			assert.Equal(t, dstMac, mechanism.vppIface.GetPhysAddress())
+		}
+	}
+}
+
+func TestServer_SharedLoopback(t *testing.T) {
+	for _, kernelMechanism := range []bool{true, false} {
+		mechanism := &interfaceServer{kernel: kernelMechanism}
+		capture := testconfigcapture.NewServer()
+		server := next.NewNetworkServiceServer(
+			vppagent.NewServer(),
+			mechanism,
+			bridge.NewServer(testvppagentcc.New(), "test-bridge", bridge.WithBVI("10.0.0.1/24")),
+			stablemac.NewServer(),
+			capture,
+		)
+		_, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{Id: "1"},
+		})
+		require.NoError(t, err)
+
+		// The mac address is set on the interface of the connection, not on the BVI shared by all connections
+		assert.NotEmpty(t, mechanism.vppIface.GetPhysAddress())
+		ifaces := capture.Config().GetVppConfig().GetInterfaces()
+		require.Len(t, ifaces, 2)
+		bvi := ifaces[1]
+		assert.Equal(t, "test-bridge-bvi", bvi.GetName())
+		assert.Empty(t, bvi.GetPhysAddress())
+	}
+}
+
+func TestServer_KeepsEthernetContext(t *testing.T) {
+	mechanism := &interfaceServer{kernel: true}
+	server := next.NewNetworkServiceServer(vppagent.NewServer(), mechanism, stablemac.NewServer())
+	conn, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "1",
+			Context: &networkservice.ConnectionContext{
+				EthernetContext: &networkservice.EthernetContext{
+					DstMac: "0a:1b:3c:4d:5e:6f",
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, stablemac.Generate("1", "src"), conn.GetContext().GetEthernetContext().GetSrcMac())
+	assert.Equal(t, "0a:1b:3c:4d:5e:6f", conn.GetContext().GetEthernetContext().GetDstMac())
+	assert.Equal(t, "0a:1b:3c:4d:5e:6f", mechanism.linuxIface.GetPhysAddress())
+}
+
+// interfaceServer plays the part of a mechanism server, which appends the interfaces before calling next
+type interfaceServer struct {
+	kernel     bool
+	linuxIface *linux.Interface
+	vppIface   *vpp.Interface
+}
+
+func (i *interfaceServer) Request(ctx context.Context, in *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	config := vppagent.Config(ctx)
+	i.vppIface = &vpp.Interface{Name: "server-1"}
+	config.GetVppConfig().Interfaces = append(config.GetVppConfig().GetInterfaces(), i.vppIface)
+	if i.kernel {
+		i.linuxIface = &linux.Interface{
+			Name: "nsm-1",
+			Link: &linuxinterfaces.Interface_Tap{
+				Tap: &linuxinterfaces.TapLink{VppTapIfName: "server-1"},
+			},
+		}
+		config.GetLinuxConfig().Interfaces = append(config.GetLinuxConfig().GetInterfaces(), i.linuxIface)
+		ctx = kernelctx.WithServerInterface(ctx, i.linuxIface)
+	}
+	return next.Server(ctx).Request(ctx, in)
+}
+
+func (i *interfaceServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	return next.Server(ctx).Close(ctx, conn)
+}
+
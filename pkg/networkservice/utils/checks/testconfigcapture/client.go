@@ -0,0 +1,68 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testconfigcapture
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+// Client - NetworkServiceClient chain element capturing the vppagent config of the last Request or Close
+type Client struct {
+	conf *configurator.Config
+	mu   sync.Mutex
+}
+
+// NewClient - returns a NetworkServiceClient chain element capturing the vppagent config of the last Request or Close
+func NewClient() *Client {
+	return &Client{}
+}
+
+// Request - captures the vppagent config and calls the next element
+func (c *Client) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	c.capture(ctx)
+	return next.Client(ctx).Request(ctx, request, opts...)
+}
+
+// Close - captures the vppagent config and calls the next element
+func (c *Client) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	c.capture(ctx)
+	return next.Client(ctx).Close(ctx, conn, opts...)
+}
+
+// Config - returns the captured vppagent config, nil if nothing was captured yet
+func (c *Client) Config() *configurator.Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conf
+}
+
+func (c *Client) capture(ctx context.Context) {
+	c.mu.Lock()
+	c.conf = vppagent.Config(ctx)
+	c.mu.Unlock()
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testconfigcapture provides chain elements capturing the vppagent config they see, for use in tests
+package testconfigcapture
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+)
+
+// Server - NetworkServiceServer chain element capturing the vppagent config of the last Request or Close
+type Server struct {
+	conf *configurator.Config
+	mu   sync.Mutex
+}
+
+// NewServer - returns a NetworkServiceServer chain element capturing the vppagent config of the last Request or Close
+func NewServer() *Server {
+	return &Server{}
+}
+
+// Request - captures the vppagent config and calls the next element
+func (s *Server) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	s.capture(ctx)
+	return next.Server(ctx).Request(ctx, request)
+}
+
+// Close - captures the vppagent config and calls the next element
+func (s *Server) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	s.capture(ctx)
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+// Config - returns the captured vppagent config, nil if nothing was captured yet
+func (s *Server) Config() *configurator.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conf
+}
+
+func (s *Server) capture(ctx context.Context) {
+	s.mu.Lock()
+	s.conf = vppagent.Config(ctx)
+	s.mu.Unlock()
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testvppagentcc provides a fake grpc.ClientConnInterface of the vppagent recording the config updates and
+// deletes sent to it, for use in tests
+package testvppagentcc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"google.golang.org/grpc"
+)
+
+// ClientConn - fake grpc.ClientConnInterface of the vppagent, recording the UpdateRequests and DeleteRequests sent to it
+type ClientConn struct {
+	updates []*configurator.UpdateRequest
+	deletes []*configurator.DeleteRequest
+	mu      sync.Mutex
+}
+
+// New - returns a fake grpc.ClientConnInterface of the vppagent, recording the UpdateRequests and DeleteRequests sent
+// to it
+func New() *ClientConn {
+	return &ClientConn{}
+}
+
+// Invoke - records UpdateRequests and DeleteRequests, all calls succeed
+func (c *ClientConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch request := args.(type) {
+	case *configurator.UpdateRequest:
+		c.updates = append(c.updates, request)
+	case *configurator.DeleteRequest:
+		c.deletes = append(c.deletes, request)
+	}
+	return nil
+}
+
+// NewStream - streams are not supported
+func (c *ClientConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("not implemented")
+}
+
+// Updates - returns the UpdateRequests received so far
+func (c *ClientConn) Updates() []*configurator.UpdateRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*configurator.UpdateRequest{}, c.updates...)
+}
+
+// Deletes - returns the DeleteRequests received so far
+func (c *ClientConn) Deletes() []*configurator.DeleteRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*configurator.DeleteRequest{}, c.deletes...)
+}
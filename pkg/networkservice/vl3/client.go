@@ -0,0 +1,118 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vl3
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/sharedconfig"
+)
+
+type vl3Client struct {
+	network        *Network
+	vppagentClient configurator.ConfiguratorServiceClient
+}
+
+// NewClient - returns a NetworkServiceClient chain element that peers the vL3 network with another vL3 instance
+// It sends the prefix of the Network as a dst route, so the peer routes it back through the connection, and routes
+// the src routes returned by the peer through the connection in the VRF of the Network.
+// The config of a client connection is committed further up the chain, after the lock of the Network is released.
+// So when the last connection of the Network is closed, the VRF and the loopback are deleted right away rather than
+// along with the rest of the connection's config.
+//             vppagentCC - grpc.ClientConnInterface of the vppagent, used to delete the VRF and the loopback on Close
+//             network - the vL3 network
+func NewClient(vppagentCC grpc.ClientConnInterface, network *Network) networkservice.NetworkServiceClient {
+	return &vl3Client{
+		network:        network,
+		vppagentClient: configurator.NewConfiguratorServiceClient(vppagentCC),
+	}
+}
+
+func (v *vl3Client) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	if request.GetConnection().GetContext() == nil {
+		request.GetConnection().Context = &networkservice.ConnectionContext{}
+	}
+	if request.GetConnection().GetContext().GetIpContext() == nil {
+		request.GetConnection().GetContext().IpContext = &networkservice.IPContext{}
+	}
+	ipContext := request.GetConnection().GetContext().GetIpContext()
+	if prefix := v.network.pool.Prefix().String(); !hasRoute(ipContext.GetDstRoutes(), prefix) {
+		ipContext.DstRoutes = append(ipContext.DstRoutes, &networkservice.Route{Prefix: prefix})
+	}
+
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	conf := vppagent.Config(ctx)
+	if len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return conn, nil
+	}
+	v.network.mu.Lock()
+	v.network.users[conn.GetId()] = true
+	v.network.mu.Unlock()
+	v.appendConfig(ctx, conn)
+	v.network.appendSharedConfig(conf.GetVppConfig())
+	return conn, nil
+}
+
+func (v *vl3Client) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	conf := vppagent.Config(ctx)
+	v.network.mu.Lock()
+	defer v.network.mu.Unlock()
+	if !v.network.users[conn.GetId()] || len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return rv, nil
+	}
+	delete(v.network.users, conn.GetId())
+	v.appendConfig(ctx, conn)
+	if len(v.network.users) == 0 {
+		// Last connection, so delete the VRF and the loopback while still holding the lock
+		sharedConfig := &vpp.ConfigData{}
+		v.network.appendSharedConfig(sharedConfig)
+		if err := sharedconfig.Delete(ctx, v.vppagentClient, sharedConfig); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+func (v *vl3Client) appendConfig(ctx context.Context, conn *networkservice.Connection) {
+	conf := vppagent.Config(ctx)
+	iface := conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1]
+	ownPrefix := v.network.pool.Prefix().String()
+	var prefixes []string
+	for _, route := range conn.GetContext().GetIpContext().GetSrcRoutes() {
+		// Our own prefix is reachable locally, no matter what the peer claims
+		if route.GetPrefix() != ownPrefix {
+			prefixes = append(prefixes, route.GetPrefix())
+		}
+	}
+	v.network.appendConnectionConfig(conf.GetVppConfig(), iface, prefixes)
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vl3 provides networkservice chain elements for a virtual L3 (vL3) network: a routed multipoint network in
+// which every connection gets an address from a prefix and can reach every other connection
+package vl3
+
+import (
+	"net"
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vppinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/interfaces"
+	vpp_l3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipam"
+)
+
+const (
+	defaultName  = "vl3"
+	defaultVrfID = 1
+)
+
+// Network - a vL3 network, shared by the vl3 server and client chain elements of an endpoint
+// All connections are placed into a single VRF and made unnumbered to a loopback holding the gateway address of the
+// Network.  The VRF and the loopback are created with the first connection and removed with the last one.
+type Network struct {
+	name    string
+	vrfID   uint32
+	pool    *ipam.Pool
+	gateway *net.IPNet
+	routes  []string
+	// allocations - addresses allocated to connections, keyed by connection id
+	allocations map[string]*net.IPNet
+	// reservations - addresses given by connections reserved in the pool, keyed by connection id
+	reservations map[string]*net.IPNet
+	// users - connections using the VRF and the loopback, keyed by connection id
+	users map[string]bool
+	mu    sync.Mutex
+}
+
+// Option - option for NewNetwork
+type Option func(n *Network)
+
+// WithName - sets the name of the loopback interface holding the gateway address.  Default "vl3"
+func WithName(name string) Option {
+	return func(n *Network) {
+		n.name = name
+	}
+}
+
+// WithVrfID - sets the id of the VRF the connections are placed into.  Default 1, below the ids vrf.Tables allocate
+func WithVrfID(vrfID uint32) Option {
+	return func(n *Network) {
+		n.vrfID = vrfID
+	}
+}
+
+// WithRoutes - adds prefixes to the routes sent to every connection, for example a prefix covering the prefixes of all
+// the vL3 instances peered with this one.  The prefix of the Network itself is always sent
+func WithRoutes(prefixes ...string) Option {
+	return func(n *Network) {
+		n.routes = append(n.routes, prefixes...)
+	}
+}
+
+// NewNetwork - returns a new vL3 Network with addresses allocated from pool
+// The first usable address of pool is used as the gateway address
+func NewNetwork(pool *ipam.Pool, opts ...Option) (*Network, error) {
+	n := &Network{
+		name:         defaultName,
+		vrfID:        defaultVrfID,
+		pool:         pool,
+		allocations:  make(map[string]*net.IPNet),
+		reservations: make(map[string]*net.IPNet),
+		users:        make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	prefix := pool.Prefix()
+	ones, bits := prefix.Mask.Size()
+	hostMask := net.CIDRMask(bits, bits)
+	if ones < bits-1 {
+		// Never hand out the network address, nor the broadcast address for IPv4
+		if err := pool.Reserve(&net.IPNet{IP: prefix.IP, Mask: hostMask}); err != nil {
+			return nil, err
+		}
+		if prefix.IP.To4() != nil {
+			broadcast := make(net.IP, len(prefix.IP))
+			for i := range prefix.IP {
+				broadcast[i] = prefix.IP[i] | ^prefix.Mask[i]
+			}
+			if err := pool.Reserve(&net.IPNet{IP: broadcast, Mask: hostMask}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	gateway, err := pool.Allocate(bits)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to allocate vl3 gateway address")
+	}
+	n.gateway = gateway
+	return n, nil
+}
+
+// assignAddresses sets the src and dst addresses and the src routes of conn.  Must be called with n.mu held.
+func (n *Network) assignAddresses(conn *networkservice.Connection) error {
+	if conn.GetContext() == nil {
+		conn.Context = &networkservice.ConnectionContext{}
+	}
+	if conn.GetContext().GetIpContext() == nil {
+		conn.GetContext().IpContext = &networkservice.IPContext{}
+	}
+	ipContext := conn.GetContext().GetIpContext()
+
+	if allocation, ok := n.allocations[conn.GetId()]; ok {
+		ipContext.SrcIpAddr = allocation.String()
+	} else if ipContext.GetSrcIpAddr() == "" {
+		var excluded []*net.IPNet
+		for _, prefix := range ipContext.GetExcludedPrefixes() {
+			_, ipNet, err := net.ParseCIDR(prefix)
+			if err != nil {
+				return errors.Wrapf(err, "invalid excluded prefix %q", prefix)
+			}
+			excluded = append(excluded, ipNet)
+		}
+		_, bits := n.gateway.Mask.Size()
+		allocation, err := n.pool.Allocate(bits, excluded...)
+		if err != nil {
+			return err
+		}
+		n.allocations[conn.GetId()] = allocation
+		ipContext.SrcIpAddr = allocation.String()
+	} else if err := n.reserve(conn.GetId(), ipContext.GetSrcIpAddr()); err != nil {
+		return err
+	}
+	ipContext.DstIpAddr = n.gateway.String()
+
+	for _, prefix := range append([]string{n.pool.Prefix().String()}, n.routes...) {
+		if !hasRoute(ipContext.GetSrcRoutes(), prefix) {
+			ipContext.SrcRoutes = append(ipContext.SrcRoutes, &networkservice.Route{Prefix: prefix})
+		}
+	}
+	return nil
+}
+
+// reserve reserves the address from srcIPAddrs of the family of the pool for the connection with connID, so that it
+// is never allocated to another connection.  Addresses outside of the pool are left alone.  Must be called with n.mu
+// held.
+func (n *Network) reserve(connID, srcIPAddrs string) error {
+	srcNet := ipaddrs.OfFamily(ipaddrs.Split(srcIPAddrs), n.gateway.IP)
+	if srcNet == nil || !n.pool.Prefix().Contains(srcNet.IP) {
+		n.release(connID)
+		return nil
+	}
+	_, bits := n.gateway.Mask.Size()
+	srcIP := srcNet.IP
+	if bits == net.IPv4len*8 {
+		srcIP = srcIP.To4()
+	}
+	block := &net.IPNet{IP: srcIP, Mask: net.CIDRMask(bits, bits)}
+	if reservation, ok := n.reservations[connID]; ok && reservation.String() == block.String() {
+		return nil
+	}
+	if err := n.pool.Reserve(block); err != nil {
+		return errors.Wrapf(err, "src address %s is not available", srcNet)
+	}
+	n.release(connID)
+	n.reservations[connID] = block
+	return nil
+}
+
+// release releases the address allocated to or reserved for the connection with connID, if any.  Must be called with
+// n.mu held.
+func (n *Network) release(connID string) {
+	if allocation, ok := n.allocations[connID]; ok {
+		n.pool.Release(allocation)
+		delete(n.allocations, connID)
+	}
+	if reservation, ok := n.reservations[connID]; ok {
+		n.pool.Release(reservation)
+		delete(n.reservations, connID)
+	}
+}
+
+// appendSharedConfig adds the loopback and the VRF shared by all connections to vppConfig.  The loopback is appended
+// after the connection's interface, so it must be called after appendConnectionConfig.
+func (n *Network) appendSharedConfig(vppConfig *vpp.ConfigData) {
+	ones, _ := n.pool.Prefix().Mask.Size()
+	vppConfig.Interfaces = append(vppConfig.Interfaces, &vpp.Interface{
+		Name:        n.name,
+		Type:        vppinterfaces.Interface_SOFTWARE_LOOPBACK,
+		Enabled:     true,
+		Vrf:         n.vrfID,
+		IpAddresses: []string{(&net.IPNet{IP: n.gateway.IP, Mask: net.CIDRMask(ones, len(n.gateway.Mask)*8)}).String()},
+	})
+	vppConfig.Vrfs = append(vppConfig.Vrfs,
+		&vpp_l3.VrfTable{
+			Id:       n.vrfID,
+			Protocol: vpp_l3.VrfTable_IPV4,
+			Label:    n.name,
+		},
+		&vpp_l3.VrfTable{
+			Id:       n.vrfID,
+			Protocol: vpp_l3.VrfTable_IPV6,
+			Label:    n.name,
+		},
+	)
+}
+
+// appendConnectionConfig places iface into the VRF, makes it unnumbered to the loopback and routes prefixes through it
+func (n *Network) appendConnectionConfig(vppConfig *vpp.ConfigData, iface *vpp.Interface, prefixes []string) {
+	iface.Vrf = n.vrfID
+	iface.IpAddresses = nil
+	iface.Unnumbered = &vppinterfaces.Interface_Unnumbered{
+		InterfaceWithIp: n.name,
+	}
+	for _, prefix := range prefixes {
+		vppConfig.Routes = append(vppConfig.Routes, &vpp.Route{
+			VrfId:             n.vrfID,
+			DstNetwork:        prefix,
+			OutgoingInterface: iface.GetName(),
+		})
+	}
+}
+
+func hasRoute(routes []*networkservice.Route, prefix string) bool {
+	for _, route := range routes {
+		if route.GetPrefix() == prefix {
+			return true
+		}
+	}
+	return false
+}
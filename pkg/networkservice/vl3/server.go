@@ -0,0 +1,103 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vl3
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+type vl3Server struct {
+	network *Network
+}
+
+// NewServer - returns a NetworkServiceServer chain element that terminates incoming connections into the vL3 network
+// It allocates the src address of each connection from the Network (or reserves it in the Network if one is already
+// set), sets the gateway of the Network as the dst address and the prefix of the Network as a src route.  On the vpp side it places the
+// connection's interface into the VRF of the Network and routes the src address and any dst routes (the prefixes of
+// peered vL3 instances) through it.
+// Requests and Closes are serialized with each other and with the Closes of NewClient until their config is committed,
+// so that the VRF and the loopback are never deleted while a connection is being committed against them.
+func NewServer(network *Network) networkservice.NetworkServiceServer {
+	return &vl3Server{
+		network: network,
+	}
+}
+
+func (v *vl3Server) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conf := vppagent.Config(ctx)
+	if len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	connID := request.GetConnection().GetId()
+
+	// The lock is held until the config is committed, so that a concurrent Close of the last connection can not delete
+	// the VRF and the loopback in the meantime
+	v.network.mu.Lock()
+	defer v.network.mu.Unlock()
+	_, existed := v.network.users[connID]
+	if err := v.network.assignAddresses(request.GetConnection()); err != nil {
+		return nil, err
+	}
+	v.network.users[connID] = true
+	v.appendConfig(ctx, request.GetConnection())
+	v.network.appendSharedConfig(conf.GetVppConfig())
+
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && !existed {
+		v.network.release(connID)
+		delete(v.network.users, connID)
+	}
+	return conn, err
+}
+
+func (v *vl3Server) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	conf := vppagent.Config(ctx)
+	// The lock is held until the config is committed, see Request
+	v.network.mu.Lock()
+	defer v.network.mu.Unlock()
+	if !v.network.users[conn.GetId()] || len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	delete(v.network.users, conn.GetId())
+	v.network.release(conn.GetId())
+	v.appendConfig(ctx, conn)
+	if len(v.network.users) == 0 {
+		// Last connection, so delete the VRF and the loopback along with the rest of the connection's config
+		v.network.appendSharedConfig(conf.GetVppConfig())
+	}
+	return next.Server(ctx).Close(ctx, conn)
+}
+
+func (v *vl3Server) appendConfig(ctx context.Context, conn *networkservice.Connection) {
+	conf := vppagent.Config(ctx)
+	iface := conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1]
+	var prefixes []string
+	if srcIP := conn.GetContext().GetIpContext().GetSrcIpAddr(); srcIP != "" {
+		prefixes = append(prefixes, srcIP)
+	}
+	for _, route := range conn.GetContext().GetIpContext().GetDstRoutes() {
+		prefixes = append(prefixes, route.GetPrefix())
+	}
+	v.network.appendConnectionConfig(conf.GetVppConfig(), iface, prefixes)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vl3_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testvppagentcc"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vl3"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipam"
+)
+
+// peerClient plays the part of a peer vL3 instance, returning its prefix as a src route
+type peerClient struct {
+	prefix string
+}
+
+func (p *peerClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn := request.GetConnection()
+	conn.GetContext().GetIpContext().SrcRoutes = []*networkservice.Route{{Prefix: p.prefix}}
+	return conn, nil
+}
+
+func (p *peerClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	return &empty.Empty{}, nil
+}
+
+func newNetwork(t *testing.T, prefix string) *vl3.Network {
+	pool, err := ipam.NewPool(prefix)
+	require.NoError(t, err)
+	network, err := vl3.NewNetwork(pool, vl3.WithName("test-vl3"), vl3.WithVrfID(5))
+	require.NoError(t, err)
+	return network
+}
+
+func routes(vppConfig *vpp.ConfigData) []string {
+	var rv []string
+	for _, route := range vppConfig.GetRoutes() {
+		rv = append(rv, route.GetDstNetwork()+" via "+route.GetOutgoingInterface())
+	}
+	return rv
+}
+
+func TestVL3Server(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		vl3.NewServer(newNetwork(t, "10.0.0.0/24")),
+		capture,
+	)
+
+	conn1, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2/32", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+	assert.Equal(t, "10.0.0.1/32", conn1.GetContext().GetIpContext().GetDstIpAddr())
+	assert.Equal(t, []*networkservice.Route{{Prefix: "10.0.0.0/24"}}, conn1.GetContext().GetIpContext().GetSrcRoutes())
+
+	conn2, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id: "2",
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					ExcludedPrefixes: []string{"10.0.0.3/32"},
+					DstRoutes:        []*networkservice.Route{{Prefix: "10.0.1.0/24"}},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.4/32", conn2.GetContext().GetIpContext().GetSrcIpAddr())
+
+	vppConfig := capture.Config().GetVppConfig()
+	require.Len(t, vppConfig.GetInterfaces(), 2)
+	assert.Equal(t, "test-vl3", vppConfig.GetInterfaces()[1].GetName())
+	assert.Equal(t, []string{"10.0.0.1/24"}, vppConfig.GetInterfaces()[1].GetIpAddresses())
+	iface := vppConfig.GetInterfaces()[0]
+	assert.Equal(t, "server-2", iface.GetName())
+	assert.Equal(t, uint32(5), iface.GetVrf())
+	assert.Equal(t, "test-vl3", iface.GetUnnumbered().GetInterfaceWithIp())
+	assert.Len(t, vppConfig.GetVrfs(), 2)
+	assert.Equal(t, []string{"10.0.0.4/32 via server-2", "10.0.1.0/24 via server-2"}, routes(vppConfig))
+
+	// Closing a connection while others remain leaves the VRF and the loopback in place
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetInterfaces(), 1)
+	assert.Len(t, capture.Config().GetVppConfig().GetVrfs(), 0)
+
+	// The released address is handed out again
+	conn3, err := server.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "3"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2/32", conn3.GetContext().GetIpContext().GetSrcIpAddr())
+
+	// Closing the last connection deletes the VRF and the loopback
+	_, err = server.Close(context.Background(), conn2)
+	require.NoError(t, err)
+	_, err = server.Close(context.Background(), conn3)
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetInterfaces(), 2)
+	assert.Len(t, capture.Config().GetVppConfig().GetVrfs(), 2)
+}
+
+func TestVL3Server_SrcIPAddrGiven(t *testing.T) {
+	server := next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		vl3.NewServer(newNetwork(t, "10.0.0.0/24")),
+	)
+	request := func(id, srcIPAddr string) *networkservice.NetworkServiceRequest {
+		return &networkservice.NetworkServiceRequest{
+			Connection: &networkservice.Connection{
+				Id: id,
+				Context: &networkservice.ConnectionContext{
+					IpContext: &networkservice.IPContext{SrcIpAddr: srcIPAddr},
+				},
+			},
+		}
+	}
+
+	// A given address is kept and never allocated to another connection
+	conn1, err := server.Request(context.Background(), request("1", "10.0.0.2/32"))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2/32", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+	conn2, err := server.Request(context.Background(), request("2", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.3/32", conn2.GetContext().GetIpContext().GetSrcIpAddr())
+
+	// Refreshing a connection keeps its address
+	conn1, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn1})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2/32", conn1.GetContext().GetIpContext().GetSrcIpAddr())
+
+	// An address in use is rejected
+	_, err = server.Request(context.Background(), request("3", "10.0.0.3/32"))
+	require.Error(t, err)
+	_, err = server.Request(context.Background(), request("3", "10.0.0.1/32"))
+	require.Error(t, err)
+
+	// Closing the connection frees its address
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	conn4, err := server.Request(context.Background(), request("4", ""))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2/32", conn4.GetContext().GetIpContext().GetSrcIpAddr())
+}
+
+func TestVL3Client(t *testing.T) {
+	cc := testvppagentcc.New()
+	capture := testconfigcapture.NewClient()
+	client := next.NewNetworkServiceClient(
+		vppagent.NewClient(),
+		capture,
+		vl3.NewClient(cc, newNetwork(t, "10.0.0.0/24")),
+		testinterfaceappender.NewClient(),
+		&peerClient{prefix: "10.0.1.0/24"},
+	)
+
+	conn, err := client.Request(context.Background(), &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{Id: "1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*networkservice.Route{{Prefix: "10.0.0.0/24"}}, conn.GetContext().GetIpContext().GetDstRoutes())
+	vppConfig := capture.Config().GetVppConfig()
+	require.Len(t, vppConfig.GetInterfaces(), 2)
+	assert.Equal(t, uint32(5), vppConfig.GetInterfaces()[0].GetVrf())
+	assert.Equal(t, "test-vl3", vppConfig.GetInterfaces()[1].GetName())
+	assert.Equal(t, []string{"10.0.1.0/24 via client-1"}, routes(vppConfig))
+
+	// Closing the last connection deletes the VRF and the loopback right away, the rest of the connection's config is
+	// deleted by the commit further up the chain
+	_, err = client.Close(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetVrfs(), 0)
+	require.Len(t, cc.Deletes(), 1)
+	deleted := cc.Deletes()[0].GetDelete().GetVppConfig()
+	assert.Len(t, deleted.GetVrfs(), 2)
+	require.Len(t, deleted.GetInterfaces(), 1)
+	assert.Equal(t, "test-vl3", deleted.GetInterfaces()[0].GetName())
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const clientSide = "client/"
+
+type vrfClient struct {
+	tables *Tables
+}
+
+// NewClient - returns a NetworkServiceClient chain element that places the vpp interface of each outgoing connection
+// into its VRF table from tables.
+// It must follow the connectioncontext elements in the chain, so that it has set the VRF table of the interface
+// before they add routes on the way back.
+func NewClient(tables *Tables) networkservice.NetworkServiceClient {
+	return &vrfClient{
+		tables: tables,
+	}
+}
+
+func (v *vrfClient) Request(ctx context.Context, request *networkservice.NetworkServiceRequest, opts ...grpc.CallOption) (*networkservice.Connection, error) {
+	conn, err := next.Client(ctx).Request(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	conf := vppagent.Config(ctx)
+	if len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return conn, nil
+	}
+	vrf, _ := v.tables.acquire(clientSide+conn.GetId(), conn)
+	conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].Vrf = vrf.id
+	appendVrfTables(conf.GetVppConfig(), vrf)
+	return conn, nil
+}
+
+func (v *vrfClient) Close(ctx context.Context, conn *networkservice.Connection, opts ...grpc.CallOption) (*empty.Empty, error) {
+	rv, err := next.Client(ctx).Close(ctx, conn, opts...)
+	if err != nil {
+		return nil, err
+	}
+	conf := vppagent.Config(ctx)
+	if len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return rv, nil
+	}
+	vrf, deleted := v.tables.close(clientSide + conn.GetId())
+	if vrf != nil {
+		conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].Vrf = vrf.id
+	}
+	// Delete the VRF tables of which this was the last user along with the rest of the connection's config
+	for _, vrf := range deleted {
+		appendVrfTables(conf.GetVppConfig(), vrf)
+	}
+	return rv, nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vrf"
+)
+
+func TestVrfClient(t *testing.T) {
+	tables := vrf.NewTables(vrf.WithPerNetworkService())
+	capture := testconfigcapture.NewClient()
+	client := next.NewNetworkServiceClient(
+		vppagent.NewClient(),
+		capture,
+		vrf.NewClient(tables),
+		testinterfaceappender.NewClient(),
+	)
+	server := newServer(tables, testconfigcapture.NewServer())
+
+	// Outgoing connections share the VRF tables with incoming ones
+	_, err := server.Request(context.Background(), request("1", "ns", "10.0.0.1/32"))
+	require.NoError(t, err)
+	conn, err := client.Request(context.Background(), request("2", "ns", "10.0.0.2/32"))
+	require.NoError(t, err)
+	vppConfig := capture.Config().GetVppConfig()
+	require.Len(t, vppConfig.GetInterfaces(), 1)
+	assert.Equal(t, "client-2", vppConfig.GetInterfaces()[0].GetName())
+	assert.Equal(t, uint32(100), vppConfig.GetInterfaces()[0].GetVrf())
+	require.Len(t, vppConfig.GetVrfs(), 2)
+	assert.Equal(t, uint32(100), vppConfig.GetVrfs()[0].GetId())
+
+	// The VRF table is still used by the incoming connection
+	_, err = client.Close(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Len(t, capture.Config().GetVppConfig().GetVrfs(), 0)
+
+	// Outgoing connections to another network service get another VRF table, deleted with them
+	conn, err = client.Request(context.Background(), request("3", "ns-2", "10.0.0.1/32"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(101), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+	_, err = client.Close(context.Background(), conn)
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetVrfs(), 2)
+	assert.Equal(t, uint32(101), capture.Config().GetVppConfig().GetVrfs()[0].GetId())
+}
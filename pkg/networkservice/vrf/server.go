@@ -0,0 +1,77 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+)
+
+const serverSide = "server/"
+
+type vrfServer struct {
+	tables *Tables
+}
+
+// NewServer - returns a NetworkServiceServer chain element that places the vpp interface of each incoming connection
+// into its VRF table from tables.
+// It must follow the mechanism elements, so that the interface is the last one in the config, and precede the
+// connectioncontext elements, so that the routes they add go into the VRF table as well.
+func NewServer(tables *Tables) networkservice.NetworkServiceServer {
+	return &vrfServer{
+		tables: tables,
+	}
+}
+
+func (v *vrfServer) Request(ctx context.Context, request *networkservice.NetworkServiceRequest) (*networkservice.Connection, error) {
+	conf := vppagent.Config(ctx)
+	if len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return next.Server(ctx).Request(ctx, request)
+	}
+	user := serverSide + request.GetConnection().GetId()
+	vrf, existed := v.tables.acquire(user, request.GetConnection())
+	conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].Vrf = vrf.id
+	appendVrfTables(conf.GetVppConfig(), vrf)
+
+	conn, err := next.Server(ctx).Request(ctx, request)
+	if err != nil && !existed {
+		v.tables.release(user)
+	}
+	return conn, err
+}
+
+func (v *vrfServer) Close(ctx context.Context, conn *networkservice.Connection) (*empty.Empty, error) {
+	conf := vppagent.Config(ctx)
+	if len(conf.GetVppConfig().GetInterfaces()) == 0 {
+		return next.Server(ctx).Close(ctx, conn)
+	}
+	vrf, deleted := v.tables.close(serverSide + conn.GetId())
+	if vrf != nil {
+		conf.GetVppConfig().GetInterfaces()[len(conf.GetVppConfig().GetInterfaces())-1].Vrf = vrf.id
+	}
+	// Delete the VRF tables of which this was the last user along with the rest of the connection's config
+	for _, vrf := range deleted {
+		appendVrfTables(conf.GetVppConfig(), vrf)
+	}
+	return next.Server(ctx).Close(ctx, conn)
+}
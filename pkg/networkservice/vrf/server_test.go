@@ -0,0 +1,154 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vrf_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkservicemesh/sdk/pkg/networkservice/core/next"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/connectioncontext/ipcontext/routes"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testconfigcapture"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/utils/checks/testinterfaceappender"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vppagent"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/networkservice/vrf"
+)
+
+func request(id, networkService, srcIPAddr string) *networkservice.NetworkServiceRequest {
+	return &networkservice.NetworkServiceRequest{
+		Connection: &networkservice.Connection{
+			Id:             id,
+			NetworkService: networkService,
+			Context: &networkservice.ConnectionContext{
+				IpContext: &networkservice.IPContext{
+					SrcIpAddr: srcIPAddr,
+				},
+			},
+		},
+	}
+}
+
+func newServer(tables *vrf.Tables, capture *testconfigcapture.Server) networkservice.NetworkServiceServer {
+	return next.NewNetworkServiceServer(
+		vppagent.NewServer(),
+		testinterfaceappender.NewServer(),
+		vrf.NewServer(tables),
+		routes.NewServer(),
+		capture,
+	)
+}
+
+func TestVrfServer_PerConnection(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := newServer(vrf.NewTables(vrf.WithFirstID(10)), capture)
+
+	// Overlapping addresses go into different VRF tables
+	conn1, err := server.Request(context.Background(), request("1", "ns", "10.0.0.1/32"))
+	require.NoError(t, err)
+	vppConfig := capture.Config().GetVppConfig()
+	assert.Equal(t, uint32(10), vppConfig.GetInterfaces()[0].GetVrf())
+	require.Len(t, vppConfig.GetVrfs(), 2)
+	assert.Equal(t, uint32(10), vppConfig.GetVrfs()[0].GetId())
+	require.Len(t, vppConfig.GetRoutes(), 1)
+	assert.Equal(t, uint32(10), vppConfig.GetRoutes()[0].GetVrfId())
+
+	conn2, err := server.Request(context.Background(), request("2", "ns", "10.0.0.1/32"))
+	require.NoError(t, err)
+	vppConfig = capture.Config().GetVppConfig()
+	assert.Equal(t, uint32(11), vppConfig.GetInterfaces()[0].GetVrf())
+	assert.Equal(t, uint32(11), vppConfig.GetRoutes()[0].GetVrfId())
+
+	// Refreshing a connection keeps its VRF table
+	conn1, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn1})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// Closing the only user of a VRF table deletes it, and its id is allocated again
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetVrfs(), 2)
+	assert.Equal(t, uint32(10), capture.Config().GetVppConfig().GetRoutes()[0].GetVrfId())
+
+	_, err = server.Request(context.Background(), request("3", "ns", "10.0.0.1/32"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	_, err = server.Close(context.Background(), conn2)
+	require.NoError(t, err)
+	assert.Len(t, capture.Config().GetVppConfig().GetVrfs(), 2)
+}
+
+func TestVrfServer_PerNetworkService(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := newServer(vrf.NewTables(vrf.WithPerNetworkService()), capture)
+
+	conn1, err := server.Request(context.Background(), request("1", "ns-1", "10.0.0.1/32"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	conn2, err := server.Request(context.Background(), request("2", "ns-1", "10.0.0.2/32"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	_, err = server.Request(context.Background(), request("3", "ns-2", "10.0.0.1/32"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(101), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// The VRF table of a network service is only deleted with its last connection
+	_, err = server.Close(context.Background(), conn1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+	assert.Len(t, capture.Config().GetVppConfig().GetVrfs(), 0)
+
+	_, err = server.Close(context.Background(), conn2)
+	require.NoError(t, err)
+	require.Len(t, capture.Config().GetVppConfig().GetVrfs(), 2)
+	assert.Equal(t, uint32(100), capture.Config().GetVppConfig().GetVrfs()[0].GetId())
+}
+
+func TestVrfServer_NetworkServiceChange(t *testing.T) {
+	capture := testconfigcapture.NewServer()
+	server := newServer(vrf.NewTables(vrf.WithPerNetworkService()), capture)
+
+	conn, err := server.Request(context.Background(), request("1", "ns-1", "10.0.0.1/32"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(100), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// Moving to another network service moves the connection to another VRF table, the old one can not be reused
+	// until it is deleted
+	conn.NetworkService = "ns-2"
+	conn, err = server.Request(context.Background(), &networkservice.NetworkServiceRequest{Connection: conn})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(101), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+	_, err = server.Request(context.Background(), request("2", "ns-3", "10.0.0.1/32"))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(102), capture.Config().GetVppConfig().GetInterfaces()[0].GetVrf())
+
+	// The old VRF table is deleted on Close along with the current one
+	_, err = server.Close(context.Background(), conn)
+	require.NoError(t, err)
+	var ids []uint32
+	for _, vrfTable := range capture.Config().GetVppConfig().GetVrfs() {
+		ids = append(ids, vrfTable.GetId())
+	}
+	assert.Equal(t, []uint32{100, 100, 101, 101}, ids)
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vrf provides networkservice chain elements that isolate connections from each other by placing their vpp
+// interfaces into VRF tables of their own
+package vrf
+
+import (
+	"sync"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+	vpp_l3 "go.ligato.io/vpp-agent/v3/proto/ligato/vpp/l3"
+)
+
+const (
+	// defaultFirstID - leaves the lower ids to fixed VRF tables configured by other elements, like the VRF of a vL3
+	// network (see vl3.WithVrfID)
+	defaultFirstID = 100
+)
+
+// Tables - VRF tables allocated to connections, shared by the vrf server and client chain elements of an endpoint
+// By default every connection gets a VRF table of its own.  With WithPerNetworkService all connections to the same
+// network service share a VRF table.  A VRF table is created with the first connection using it and removed with the
+// last one.
+type Tables struct {
+	firstID           uint32
+	perNetworkService bool
+	// tables - allocated VRF tables, keyed by connection id or network service name
+	tables map[string]*table
+	// users - the key of the VRF table used by each user, keyed by side and connection id
+	users map[string]string
+	// leftBehind - VRF tables whose last user moved to another VRF table, keyed by that user.  The vppagent config of
+	// a Request can not delete anything, so they are deleted along with the rest of the user's config on Close.
+	leftBehind map[string][]*table
+	mu         sync.Mutex
+}
+
+type table struct {
+	id    uint32
+	users int
+}
+
+// Option - option for NewTables
+type Option func(t *Tables)
+
+// WithPerNetworkService - allocates one VRF table per network service instead of one per connection
+func WithPerNetworkService() Option {
+	return func(t *Tables) {
+		t.perNetworkService = true
+	}
+}
+
+// WithFirstID - sets the lowest VRF table id allocated, so that ids below it can be used by other elements.  Default 100
+func WithFirstID(id uint32) Option {
+	return func(t *Tables) {
+		t.firstID = id
+	}
+}
+
+// NewTables - returns new VRF Tables
+func NewTables(opts ...Option) *Tables {
+	t := &Tables{
+		firstID:    defaultFirstID,
+		tables:     make(map[string]*table),
+		users:      make(map[string]string),
+		leftBehind: make(map[string][]*table),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *Tables) key(conn *networkservice.Connection) string {
+	if t.perNetworkService {
+		return conn.GetNetworkService()
+	}
+	return conn.GetId()
+}
+
+// acquire returns the VRF table of conn for user, allocating it if needed, and whether user already held it
+// If user moves to another VRF table (its connection changed network service) and was the last user of the old one,
+// the old one is left behind for close to return.
+func (t *Tables) acquire(user string, conn *networkservice.Connection) (vrf *table, existed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := t.key(conn)
+	if oldKey, ok := t.users[user]; ok {
+		if oldKey == key {
+			return t.tables[key], true
+		}
+		if old, last := t.releaseLocked(user); last {
+			t.leftBehind[user] = append(t.leftBehind[user], old)
+		}
+	}
+	vrf, ok := t.tables[key]
+	if !ok {
+		vrf = &table{id: t.freeID()}
+		t.tables[key] = vrf
+	}
+	vrf.users++
+	t.users[user] = key
+	return vrf, false
+}
+
+// release releases the VRF table held by user and returns it along with whether user was its last user
+func (t *Tables) release(user string) (vrf *table, last bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.releaseLocked(user)
+}
+
+// close releases the VRF table held by user for good.  It returns the table, nil if user held none, and the tables to
+// delete: the table if user was its last user and any tables user left behind.
+func (t *Tables) close(user string) (vrf *table, deleted []*table) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	vrf, last := t.releaseLocked(user)
+	deleted = t.leftBehind[user]
+	delete(t.leftBehind, user)
+	if last {
+		deleted = append(deleted, vrf)
+	}
+	return vrf, deleted
+}
+
+func (t *Tables) releaseLocked(user string) (vrf *table, last bool) {
+	key, ok := t.users[user]
+	if !ok {
+		return nil, false
+	}
+	delete(t.users, user)
+	vrf = t.tables[key]
+	vrf.users--
+	if vrf.users > 0 {
+		return vrf, false
+	}
+	delete(t.tables, key)
+	return vrf, true
+}
+
+// freeID returns the lowest VRF table id not allocated yet.  Must be called with t.mu held.
+func (t *Tables) freeID() uint32 {
+	used := make(map[uint32]bool, len(t.tables))
+	for _, vrf := range t.tables {
+		used[vrf.id] = true
+	}
+	// Left behind tables still exist in vpp until they are deleted
+	for _, vrfs := range t.leftBehind {
+		for _, vrf := range vrfs {
+			used[vrf.id] = true
+		}
+	}
+	id := t.firstID
+	for used[id] {
+		id++
+	}
+	return id
+}
+
+// appendVrfTables adds the IPv4 and IPv6 tables of vrf to vppConfig
+func appendVrfTables(vppConfig *vpp.ConfigData, vrf *table) {
+	vppConfig.Vrfs = append(vppConfig.Vrfs,
+		&vpp_l3.VrfTable{
+			Id:       vrf.id,
+			Protocol: vpp_l3.VrfTable_IPV4,
+		},
+		&vpp_l3.VrfTable{
+			Id:       vrf.id,
+			Protocol: vpp_l3.VrfTable_IPV6,
+		},
+	)
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+// Package ifname provides helpers for choosing linux interface names
+// Linux limits interface names to kernel.LinuxIfMaxLength (15) characters.  Names derived from connection ids are
+// almost always longer, and truncating them makes connections whose ids share a prefix collide, so long names are
+// shortened to a prefix followed by a hash of the id instead.
+package ifname
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/networkservicemesh/api/pkg/api/networkservice/mechanisms/kernel"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+const (
+	// NameKey - kernel mechanism parameter recording the interface name chosen for a connection, so that refreshes
+	// keep it even if the Names choosing it were lost in a restart
+	NameKey    = "ifname"
+	hashLength = 8
+	separator  = "-"
+)
+
+// Generate - returns a deterministic interface name for id of at most kernel.LinuxIfMaxLength characters
+// prefix + "-" + id is used as is if it is a valid interface name, otherwise prefix is cut to fit and followed by the
+// first characters of the sha256 hash of id.
+func Generate(prefix, id string) string {
+	if name := prefix + separator + id; Valid(name) {
+		return name
+	}
+	sum := sha256.Sum256([]byte(id))
+	maxPrefixLength := kernel.LinuxIfMaxLength - len(separator) - hashLength
+	if len(prefix) > maxPrefixLength {
+		prefix = prefix[:maxPrefixLength]
+	}
+	return prefix + separator + hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// Valid - returns true if name is usable as a linux interface name
+func Valid(name string) bool {
+	if name == "" || len(name) > kernel.LinuxIfMaxLength || name == "." || name == ".." {
+		return false
+	}
+	for _, c := range name {
+		if c == '/' || c == ':' || c > unicode.MaxASCII || unicode.IsSpace(c) || !unicode.IsPrint(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Exists - returns true if an interface named name exists in the network namespace netnsFilename
+// The interfaces are read from /proc/<pid>/net/dev.  Network namespaces given by any other file, like
+// /var/run/netns/<name>, are looked up by inode among the network namespaces of the running processes first.  A
+// network namespace no process is running in can not be checked, so false is returned for it.
+func Exists(netnsFilename, name string) (bool, error) {
+	nsDir := filepath.Dir(filepath.Clean(netnsFilename))
+	if filepath.Base(netnsFilename) != "net" || filepath.Base(nsDir) != "ns" {
+		inode, err := netnsinode.GetNetNSInodeNum(netnsFilename)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to get the inode of network namespace %s", netnsFilename)
+		}
+		procFilename, err := netnsinode.LinuxNetNSFileName(strconv.FormatUint(inode, 10))
+		if err != nil {
+			return false, nil
+		}
+		nsDir = filepath.Dir(procFilename)
+	}
+	devFilename := filepath.Join(filepath.Dir(nsDir), "net", "dev")
+	file, err := os.Open(devFilename) // #nosec
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to read interfaces from %s", devFilename)
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// The first two lines are headers, every other line is "<name>: <statistics>"
+		fields := strings.SplitN(scanner.Text(), ":", 2)
+		if len(fields) == 2 && strings.TrimSpace(fields[0]) == name {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, errors.Wrapf(err, "unable to read interfaces from %s", devFilename)
+	}
+	return false, nil
+}
+
+// Names - interface names chosen for connections, shared by the Request and Close of a chain element
+// A refreshed connection keeps the name chosen by its first Request, although the interface exists by then.  Chain
+// elements record the chosen name in the NameKey parameter of the mechanism, so that it is kept after a restart too.
+type Names struct {
+	prefix string
+	// names - chosen interface names, keyed by connection id
+	names map[string]string
+	// netnsFilenames - network namespaces the names are chosen in, keyed by connection id
+	netnsFilenames map[string]string
+	mu             sync.Mutex
+}
+
+// NewNames - returns new Names, generating names starting with prefix
+func NewNames(prefix string) *Names {
+	return &Names{
+		prefix:         prefix,
+		names:          make(map[string]string),
+		netnsFilenames: make(map[string]string),
+	}
+}
+
+// Get - returns the interface name for the connection with id in the network namespace netnsFilename
+// chosen, the name recorded in the NameKey parameter by an earlier Request, is used if it is a valid interface name:
+// an interface with that name is the connection's own.  Otherwise requested is used if it is a valid interface name
+// not known to be used in the network namespace already, and a name is generated from id if it is not.  Whichever it
+// is, a name held by another connection into the same network namespace is never used: the next candidate is, and a
+// generated name held already is generated again from id and a counter.
+func (n *Names) Get(id, requested, chosen, netnsFilename string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if name, ok := n.names[id]; ok {
+		return name
+	}
+	var name string
+	switch {
+	case Valid(chosen) && !n.held(id, chosen, netnsFilename):
+		name = chosen
+	case Valid(requested) && !n.held(id, requested, netnsFilename) && !exists(netnsFilename, requested):
+		name = requested
+	default:
+		name = Generate(n.prefix, id)
+		for i := 1; n.held(id, name, netnsFilename); i++ {
+			name = Generate(n.prefix, id+separator+strconv.Itoa(i))
+		}
+	}
+	n.names[id] = name
+	n.netnsFilenames[id] = netnsFilename
+	return name
+}
+
+// held returns true if name is held by a connection other than the one with id in the network namespace
+// netnsFilename.  Must be called with n.mu held.
+func (n *Names) held(id, name, netnsFilename string) bool {
+	for otherID, otherName := range n.names {
+		if otherID != id && otherName == name && n.netnsFilenames[otherID] == netnsFilename {
+			return true
+		}
+	}
+	return false
+}
+
+// exists returns true if an interface named name is known to exist in the network namespace netnsFilename.  Conflicts
+// are detected on a best effort basis, an unreadable network namespace does not count as one.
+func exists(netnsFilename, name string) bool {
+	found, err := Exists(netnsFilename, name)
+	return err == nil && found
+}
+
+// Has - returns true if a name has been chosen for the connection with id
+func (n *Names) Has(id string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, ok := n.names[id]
+	return ok
+}
+
+// Delete - forgets the interface name for the connection with id
+func (n *Names) Delete(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.names, id)
+	delete(n.netnsFilenames, id)
+}
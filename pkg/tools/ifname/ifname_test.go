@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package ifname_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ifname"
+)
+
+const netDev = `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo:       0       0    0    0    0     0          0         0        0       0    0    0    0     0       0          0
+  eth0:    1296      16    0    0    0     0          0         0      936      12    0    0    0     0       0          0
+`
+
+// netns returns the filename of the network namespace of a fake process, whose net/dev lists lo and eth0
+func netns(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "ifname")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "12", "ns"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "12", "net"), 0o700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "12", "ns", "net"), nil, 0o600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "12", "net", "dev"), []byte(netDev), 0o600))
+	return filepath.Join(dir, "12", "ns", "net")
+}
+
+func TestGenerate(t *testing.T) {
+	assert.Equal(t, "server-1", ifname.Generate("server", "1"))
+
+	name1 := ifname.Generate("server", "a51c4f3e-0e4c-4f6b-9b36-5b7a4b3a4c01")
+	name2 := ifname.Generate("server", "a51c4f3e-0e4c-4f6b-9b36-5b7a4b3a4c02")
+	assert.Len(t, name1, 15)
+	assert.Regexp(t, "^server-[0-9a-f]{8}$", name1)
+	assert.NotEqual(t, name1, name2)
+	assert.Equal(t, name1, ifname.Generate("server", "a51c4f3e-0e4c-4f6b-9b36-5b7a4b3a4c01"))
+
+	assert.Regexp(t, "^longpr-[0-9a-f]{8}$", ifname.Generate("longprefix", "12345"))
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, ifname.Valid("eth0"))
+	assert.True(t, ifname.Valid("nsm-0123456789a"))
+	assert.False(t, ifname.Valid(""))
+	assert.False(t, ifname.Valid("nsm-0123456789ab"))
+	assert.False(t, ifname.Valid("nsm/1"))
+	assert.False(t, ifname.Valid("nsm:1"))
+	assert.False(t, ifname.Valid("nsm 1"))
+	assert.False(t, ifname.Valid(".."))
+}
+
+func TestExists(t *testing.T) {
+	filename := netns(t)
+	exists, err := ifname.Exists(filename, "eth0")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = ifname.Exists(filename, "eth1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Network namespaces given by any other file are looked up by inode among those of the running processes
+	link := filepath.Join(filepath.Dir(filename), "link")
+	require.NoError(t, os.Symlink("/proc/self/ns/net", link))
+	exists, err = ifname.Exists(link, "lo")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = ifname.Exists(filepath.Join(filepath.Dir(filename), "missing"), "eth0")
+	assert.Error(t, err)
+}
+
+func TestNames(t *testing.T) {
+	filename := netns(t)
+	names := ifname.NewNames("nsm")
+
+	// The requested name is honoured unless it is in use already
+	assert.Equal(t, "nsm0", names.Get("1", "nsm0", "", filename))
+	assert.Equal(t, ifname.Generate("nsm", "2"), names.Get("2", "eth0", "", filename))
+	assert.Equal(t, ifname.Generate("nsm", "3"), names.Get("3", "", "", filename))
+	assert.Equal(t, ifname.Generate("nsm", "4"), names.Get("4", "nsm-0123456789ab", "", filename))
+
+	// A refreshed connection keeps its name, even once it shows up in the network namespace
+	assert.Equal(t, "nsm0", names.Get("1", "nsm0", "", filename))
+
+	assert.True(t, names.Has("2"))
+	names.Delete("2")
+	assert.False(t, names.Has("2"))
+	assert.Equal(t, "eth1", names.Get("2", "eth1", "", filename))
+
+	// The name recorded by an earlier Request is kept after a restart, although the interface exists
+	names = ifname.NewNames("nsm")
+	assert.Equal(t, "eth0", names.Get("1", "eth0", "eth0", filename))
+}
+
+func TestNames_Held(t *testing.T) {
+	filename := netns(t)
+	names := ifname.NewNames("nsm")
+	assert.Equal(t, "nsm0", names.Get("1", "nsm0", "", filename))
+
+	// Names held by other connections into the same network namespace are not used, whether chosen or requested
+	assert.Equal(t, ifname.Generate("nsm", "2"), names.Get("2", "nsm0", "nsm0", filename))
+	assert.Equal(t, ifname.Generate("nsm", "3"), names.Get("3", "nsm0", "", filename))
+
+	// Neither are generated ones
+	assert.Equal(t, ifname.Generate("nsm", "4"), names.Get("5", ifname.Generate("nsm", "4"), "", filename))
+	assert.Equal(t, ifname.Generate("nsm", "4-1"), names.Get("4", "", "", filename))
+
+	// Connections into other network namespaces do not hold names
+	assert.Equal(t, "nsm0", names.Get("6", "nsm0", "", netns(t)))
+	names.Delete("1")
+	assert.Equal(t, "nsm0", names.Get("7", "", "nsm0", filename))
+}
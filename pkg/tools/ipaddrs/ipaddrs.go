@@ -0,0 +1,82 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipaddrs provides helpers for handling the addresses of a connection context
+// The IPContext of a connection has a single src and a single dst address field.  Multiple addresses, for example an
+// IPv4 and an IPv6 address of a dual-stack connection, are given as a comma separated list of addresses in CIDR
+// notation: "10.0.0.1/32,fd00::1/128".
+package ipaddrs
+
+import (
+	"net"
+	"strings"
+)
+
+// Split - returns the addresses in the comma separated list addrs, skipping empty entries
+func Split(addrs string) []string {
+	var rv []string
+	for _, addr := range strings.Split(addrs, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			rv = append(rv, addr)
+		}
+	}
+	return rv
+}
+
+// Append - appends the addrs not yet in ipAddresses to ipAddresses
+func Append(ipAddresses []string, addrs ...string) []string {
+	for _, addr := range addrs {
+		if !contains(ipAddresses, addr) {
+			ipAddresses = append(ipAddresses, addr)
+		}
+	}
+	return ipAddresses
+}
+
+// WithoutLinkLocal - returns addrs without IPv6 link-local addresses (fe80::/10)
+func WithoutLinkLocal(addrs []string) []string {
+	var rv []string
+	for _, addr := range addrs {
+		if ip, _, err := net.ParseCIDR(addr); err == nil && ip.To4() == nil && ip.IsLinkLocalUnicast() {
+			continue
+		}
+		rv = append(rv, addr)
+	}
+	return rv
+}
+
+// OfFamily - returns the first of addrs with the same address family as ip, or nil if there is none
+func OfFamily(addrs []string, ip net.IP) *net.IPNet {
+	for _, addr := range addrs {
+		addrIP, addrNet, err := net.ParseCIDR(addr)
+		if err != nil {
+			continue
+		}
+		if (addrIP.To4() != nil) == (ip.To4() != nil) {
+			return &net.IPNet{IP: addrIP, Mask: addrNet.Mask}
+		}
+	}
+	return nil
+}
+
+func contains(addrs []string, addr string) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipaddrs_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipaddrs"
+)
+
+func TestSplit(t *testing.T) {
+	assert.Nil(t, ipaddrs.Split(""))
+	assert.Equal(t, []string{"10.0.0.1/32"}, ipaddrs.Split("10.0.0.1/32"))
+	assert.Equal(t, []string{"10.0.0.1/32", "fd00::1/128"}, ipaddrs.Split("10.0.0.1/32, fd00::1/128,"))
+}
+
+func TestAppend(t *testing.T) {
+	assert.Equal(t,
+		[]string{"10.0.0.1/32", "fd00::1/128"},
+		ipaddrs.Append([]string{"10.0.0.1/32"}, "10.0.0.1/32", "fd00::1/128"),
+	)
+}
+
+func TestWithoutLinkLocal(t *testing.T) {
+	assert.Equal(t,
+		[]string{"10.0.0.1/32", "fd00::1/128"},
+		ipaddrs.WithoutLinkLocal([]string{"10.0.0.1/32", "fe80::1/64", "fd00::1/128"}),
+	)
+}
+
+func TestOfFamily(t *testing.T) {
+	addrs := []string{"10.0.0.1/24", "fd00::1/64"}
+	assert.Equal(t, "10.0.0.1/24", ipaddrs.OfFamily(addrs, net.ParseIP("192.168.0.1")).String())
+	assert.Equal(t, "fd00::1/64", ipaddrs.OfFamily(addrs, net.ParseIP("fd01::")).String())
+	assert.Nil(t, ipaddrs.OfFamily(addrs[:1], net.ParseIP("fd01::")))
+}
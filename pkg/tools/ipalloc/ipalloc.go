@@ -0,0 +1,133 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipalloc provides helpers for registering the addresses of a connection with the vppagent netalloc plugin
+// Every address of a connection is put into its own netalloc network, named after the connection id, the address
+// family and the position of the address within that family: "<id>-ipv4-0".  Both ends of the connection allocate
+// their address in the same network, and interfaces and routes refer to the allocations symbolically
+// ("alloc:<network>/<interface>"), which lets vppagent order the configuration of interfaces, addresses and routes
+// itself.
+package ipalloc
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"go.ligato.io/vpp-agent/v3/proto/ligato/netalloc"
+)
+
+// Allocations - returns an allocation on ifaceName for each of ipAddrs.  The gateway of an allocation is the address
+// of the same family from gwAddrs, if any
+func Allocations(connID, ifaceName string, ipAddrs, gwAddrs []string) []*netalloc.IPAllocation {
+	var rv []*netalloc.IPAllocation
+	positions := make(map[string]int)
+	for _, ipAddr := range ipAddrs {
+		ip, ipNet := parse(ipAddr)
+		if ip == nil {
+			continue
+		}
+		family := familyOf(ip)
+		allocation := &netalloc.IPAllocation{
+			NetworkName:   fmt.Sprintf("%s-%s-%d", connID, family, positions[family]),
+			InterfaceName: ifaceName,
+			Address:       (&net.IPNet{IP: ip, Mask: ipNet.Mask}).String(),
+		}
+		positions[family]++
+		for _, gwAddr := range gwAddrs {
+			if gwIP, _ := parse(gwAddr); gwIP != nil && family == familyOf(gwIP) {
+				allocation.Gw = gwIP.String()
+				break
+			}
+		}
+		rv = append(rv, allocation)
+	}
+	return rv
+}
+
+// Append - appends allocations to config, replacing those already made for the same network and interface, and
+// returns the references to them.  Without a config the literal addresses are returned
+func Append(config *netalloc.ConfigData, allocations ...*netalloc.IPAllocation) []string {
+	var rv []string
+	for _, allocation := range allocations {
+		if config == nil {
+			rv = append(rv, allocation.GetAddress())
+			continue
+		}
+		if i := index(config, allocation.GetNetworkName(), allocation.GetInterfaceName()); i >= 0 {
+			config.IpAddresses[i] = allocation
+		} else {
+			config.IpAddresses = append(config.GetIpAddresses(), allocation)
+		}
+		rv = append(rv, Ref(allocation.GetNetworkName(), allocation.GetInterfaceName()))
+	}
+	return rv
+}
+
+// GWRef - returns the reference to the gateway of the allocation on ifaceName of the same family as ip, or "" if
+// there is no such allocation with a gateway in config
+func GWRef(config *netalloc.ConfigData, ifaceName string, ip net.IP) string {
+	for _, allocation := range config.GetIpAddresses() {
+		if allocation.GetInterfaceName() != ifaceName || allocation.GetGw() == "" {
+			continue
+		}
+		if allocIP, _ := parse(allocation.GetAddress()); allocIP != nil && familyOf(allocIP) == familyOf(ip) {
+			return Ref(allocation.GetNetworkName(), ifaceName) + netalloc.AllocRefGWSuffix
+		}
+	}
+	return ""
+}
+
+// Ref - returns the reference to the address allocated to ifaceName in network
+func Ref(network, ifaceName string) string {
+	return netalloc.AllocRefPrefix + network + "/" + ifaceName
+}
+
+func index(config *netalloc.ConfigData, network, ifaceName string) int {
+	for i, allocation := range config.GetIpAddresses() {
+		if allocation.GetNetworkName() == network && allocation.GetInterfaceName() == ifaceName {
+			return i
+		}
+	}
+	return -1
+}
+
+// parse - parses ipAddr with or without a prefix length, an address without one is a host address
+func parse(ipAddr string) (net.IP, *net.IPNet) {
+	if !strings.Contains(ipAddr, "/") {
+		ip := net.ParseIP(ipAddr)
+		if ip == nil {
+			return nil, nil
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			bits = 8 * net.IPv4len
+		}
+		return ip, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+	ip, ipNet, err := net.ParseCIDR(ipAddr)
+	if err != nil {
+		return nil, nil
+	}
+	return ip, ipNet
+}
+
+func familyOf(ip net.IP) string {
+	if ip.To4() != nil {
+		return "ipv4"
+	}
+	return "ipv6"
+}
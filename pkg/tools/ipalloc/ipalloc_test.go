@@ -0,0 +1,50 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipalloc_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/netalloc"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipalloc"
+)
+
+func TestAllocations(t *testing.T) {
+	allocations := ipalloc.Allocations("1", "nsm-1", []string{"10.0.0.1/30", "fd00::1/126", "10.0.1.1"}, []string{"fd00::2/126", "10.0.0.2/30"})
+	assert.Equal(t, []*netalloc.IPAllocation{
+		{NetworkName: "1-ipv4-0", InterfaceName: "nsm-1", Address: "10.0.0.1/30", Gw: "10.0.0.2"},
+		{NetworkName: "1-ipv6-0", InterfaceName: "nsm-1", Address: "fd00::1/126", Gw: "fd00::2"},
+		{NetworkName: "1-ipv4-1", InterfaceName: "nsm-1", Address: "10.0.1.1/32", Gw: "10.0.0.2"},
+	}, allocations)
+}
+
+func TestAppend(t *testing.T) {
+	config := &netalloc.ConfigData{}
+	allocations := ipalloc.Allocations("1", "nsm-1", []string{"10.0.0.1/30", "fd00::1/126"}, []string{"10.0.0.2/30"})
+	assert.Equal(t, []string{"alloc:1-ipv4-0/nsm-1", "alloc:1-ipv6-0/nsm-1"}, ipalloc.Append(config, allocations...))
+	assert.Equal(t, []string{"alloc:1-ipv4-0/nsm-1", "alloc:1-ipv6-0/nsm-1"}, ipalloc.Append(config, allocations...))
+	assert.Equal(t, allocations, config.GetIpAddresses())
+
+	assert.Equal(t, "alloc:1-ipv4-0/nsm-1/GW", ipalloc.GWRef(config, "nsm-1", net.ParseIP("10.1.0.0")))
+	assert.Equal(t, "", ipalloc.GWRef(config, "nsm-1", net.ParseIP("fd10::")))
+	assert.Equal(t, "", ipalloc.GWRef(config, "nsm-2", net.ParseIP("10.1.0.0")))
+
+	assert.Equal(t, []string{"10.0.0.1/30", "fd00::1/126"}, ipalloc.Append(nil, allocations...))
+}
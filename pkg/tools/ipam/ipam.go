@@ -0,0 +1,159 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipam provides an allocator of IP address blocks from a prefix
+package ipam
+
+import (
+	"math/big"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Pool - allocates non overlapping blocks of addresses from a prefix
+type Pool struct {
+	prefix    *net.IPNet
+	allocated []*net.IPNet
+	mu        sync.Mutex
+}
+
+// NewPool - returns a new Pool of the addresses in prefix
+//             prefix - prefix in CIDR notation, for example "10.0.0.0/24"
+func NewPool(prefix string) (*Pool, error) {
+	_, ipNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid prefix %q", prefix)
+	}
+	return &Pool{
+		prefix: ipNet,
+	}, nil
+}
+
+// Prefix - returns the prefix of the Pool
+func (p *Pool) Prefix() *net.IPNet {
+	return &net.IPNet{
+		IP:   dup(p.prefix.IP),
+		Mask: append(net.IPMask{}, p.prefix.Mask...),
+	}
+}
+
+// Allocate - allocates the first free block with prefix length ones that overlaps neither an allocated block nor any of
+// the excluded prefixes
+func (p *Pool) Allocate(ones int, excluded ...*net.IPNet) (*net.IPNet, error) {
+	prefixOnes, bits := p.prefix.Mask.Size()
+	if ones < prefixOnes || ones > bits {
+		return nil, errors.Errorf("can not allocate a /%d from %s", ones, p.prefix)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	last := lastIP(p.prefix)
+	candidate := toInt(p.prefix.IP)
+	for candidate.Cmp(last) <= 0 {
+		block := &net.IPNet{
+			IP:   toIP(candidate, len(p.prefix.IP)),
+			Mask: net.CIDRMask(ones, bits),
+		}
+		overlapping := p.overlapping(block, excluded)
+		if overlapping == nil {
+			p.allocated = append(p.allocated, block)
+			return block, nil
+		}
+		// Skip past the end of whatever overlaps, keeping the candidate aligned to the block size
+		next := new(big.Int).Add(maxInt(lastIP(block), lastIP(overlapping)), big.NewInt(1))
+		candidate = alignUp(next, uint(bits-ones))
+	}
+	return nil, errors.Errorf("no free /%d left in %s", ones, p.prefix)
+}
+
+// Reserve - marks block as allocated, so that Allocate never returns a block overlapping it
+func (p *Pool) Reserve(block *net.IPNet) error {
+	if !p.prefix.Contains(block.IP) {
+		return errors.Errorf("%s is not in %s", block, p.prefix)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if overlapping := p.overlapping(block, nil); overlapping != nil {
+		return errors.Errorf("%s overlaps already allocated %s", block, overlapping)
+	}
+	p.allocated = append(p.allocated, block)
+	return nil
+}
+
+// Release - returns a block previously returned by Allocate or passed to Reserve to the Pool
+func (p *Pool) Release(block *net.IPNet) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, allocated := range p.allocated {
+		if allocated.String() == block.String() {
+			p.allocated = append(p.allocated[:i], p.allocated[i+1:]...)
+			return
+		}
+	}
+}
+
+// overlapping returns the first allocated block or excluded prefix overlapping block.  Must be called with p.mu held.
+func (p *Pool) overlapping(block *net.IPNet, excluded []*net.IPNet) *net.IPNet {
+	for _, ipNets := range [][]*net.IPNet{p.allocated, excluded} {
+		for _, ipNet := range ipNets {
+			if ipNet.Contains(block.IP) || block.Contains(ipNet.IP.Mask(ipNet.Mask)) {
+				return ipNet
+			}
+		}
+	}
+	return nil
+}
+
+func toInt(ip net.IP) *big.Int {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return new(big.Int).SetBytes(ip)
+}
+
+func toIP(i *big.Int, size int) net.IP {
+	b := i.Bytes()
+	ip := make(net.IP, size)
+	copy(ip[size-len(b):], b)
+	return ip
+}
+
+func lastIP(ipNet *net.IPNet) *big.Int {
+	ones, bits := ipNet.Mask.Size()
+	hostMask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits-ones)), big.NewInt(1))
+	return new(big.Int).Or(toInt(ipNet.IP.Mask(ipNet.Mask)), hostMask)
+}
+
+func alignUp(i *big.Int, hostBits uint) *big.Int {
+	rv := new(big.Int).Rsh(i, hostBits)
+	if new(big.Int).Lsh(rv, hostBits).Cmp(i) != 0 {
+		rv.Add(rv, big.NewInt(1))
+	}
+	return rv.Lsh(rv, hostBits)
+}
+
+func maxInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func dup(ip net.IP) net.IP {
+	return append(net.IP{}, ip...)
+}
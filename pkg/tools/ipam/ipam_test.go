@@ -0,0 +1,91 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/ipam"
+)
+
+func parseCIDR(t *testing.T, cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	require.NoError(t, err)
+	return ipNet
+}
+
+func TestPool_Allocate(t *testing.T) {
+	pool, err := ipam.NewPool("10.0.0.0/29")
+	require.NoError(t, err)
+	require.NoError(t, pool.Reserve(parseCIDR(t, "10.0.0.0/32")))
+
+	block, err := pool.Allocate(32)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1/32", block.String())
+
+	// Blocks are aligned to their size
+	block, err = pool.Allocate(31)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2/31", block.String())
+
+	// Excluded prefixes are skipped
+	block, err = pool.Allocate(32, parseCIDR(t, "10.0.0.4/31"))
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.6/32", block.String())
+
+	pool.Release(parseCIDR(t, "10.0.0.2/31"))
+	block, err = pool.Allocate(31)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2/31", block.String())
+
+	block, err = pool.Allocate(31)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.4/31", block.String())
+
+	_, err = pool.Allocate(32)
+	require.NoError(t, err)
+	_, err = pool.Allocate(32)
+	assert.Error(t, err)
+}
+
+func TestPool_IPv6(t *testing.T) {
+	pool, err := ipam.NewPool("fd00::/120")
+	require.NoError(t, err)
+
+	block, err := pool.Allocate(127, parseCIDR(t, "fd00::/127"))
+	require.NoError(t, err)
+	assert.Equal(t, "fd00::2/127", block.String())
+
+	block, err = pool.Allocate(128)
+	require.NoError(t, err)
+	assert.Equal(t, "fd00::/128", block.String())
+
+	_, err = pool.Allocate(64)
+	assert.Error(t, err)
+}
+
+func TestPool_Reserve(t *testing.T) {
+	pool, err := ipam.NewPool("10.0.0.0/24")
+	require.NoError(t, err)
+	require.NoError(t, pool.Reserve(parseCIDR(t, "10.0.0.0/30")))
+	assert.Error(t, pool.Reserve(parseCIDR(t, "10.0.0.2/32")))
+	assert.Error(t, pool.Reserve(parseCIDR(t, "10.0.1.0/32")))
+}
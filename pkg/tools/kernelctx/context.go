@@ -14,21 +14,30 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package kernelctx enables the server side kernel interface to be stored in the context
+// Package kernelctx enables the server and client side kernel interfaces to be stored in the context
 package kernelctx
 
 import (
 	"context"
 
 	linuxinterfaces "go.ligato.io/vpp-agent/v3/proto/ligato/linux/interfaces"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
 )
 
 type contextKeyType string
 
 const (
 	serverInterfaceKey contextKeyType = "serverInterface"
+	clientInterfaceKey contextKeyType = "clientInterface"
 )
 
+// clientInterfaceSlot - holds the client interface.  Client chain elements apply the connection context after the
+// kernel mechanism client further down the chain has created the interface, so the interface can not be passed down
+// the context like the server interface.  Instead a slot is passed down and filled in on the way back.
+type clientInterfaceSlot struct {
+	iface *linuxinterfaces.Interface
+}
+
 // WithServerInterface - Server interface iface to the context
 func WithServerInterface(ctx context.Context, iface *linuxinterfaces.Interface) context.Context {
 	return context.WithValue(ctx, serverInterfaceKey, iface)
@@ -42,3 +51,46 @@ func ServerInterface(ctx context.Context) *linuxinterfaces.Interface {
 	}
 	return nil
 }
+
+// WithClientInterface - returns ctx with a slot for the client interface, which the kernel mechanism client further
+// down the chain fills in with SetClientInterface.  If ctx has a slot already it is returned as is, so that all the
+// elements of a chain share a single slot
+func WithClientInterface(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(clientInterfaceKey).(*clientInterfaceSlot); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, clientInterfaceKey, &clientInterfaceSlot{})
+}
+
+// SetClientInterface - store client interface iface in the slot of the context, if it has one
+func SetClientInterface(ctx context.Context, iface *linuxinterfaces.Interface) {
+	if slot, ok := ctx.Value(clientInterfaceKey).(*clientInterfaceSlot); ok {
+		slot.iface = iface
+	}
+}
+
+// ClientInterface - retrieve client interface from the context
+func ClientInterface(ctx context.Context) *linuxinterfaces.Interface {
+	if slot, ok := ctx.Value(clientInterfaceKey).(*clientInterfaceSlot); ok {
+		return slot.iface
+	}
+	return nil
+}
+
+// VppInterface - returns the vpp side of the kernel interface iface from vppConfig, nil if there is none.  The vpp side
+// of a tap is named by the tap link, that of a veth pair has the same name as the kernel interface.
+func VppInterface(vppConfig *vpp.ConfigData, iface *linuxinterfaces.Interface) *vpp.Interface {
+	if iface == nil {
+		return nil
+	}
+	name := iface.GetName()
+	if tapName := iface.GetTap().GetVppTapIfName(); tapName != "" {
+		name = tapName
+	}
+	for _, vppIface := range vppConfig.GetInterfaces() {
+		if vppIface.GetName() == name {
+			return vppIface
+		}
+	}
+	return nil
+}
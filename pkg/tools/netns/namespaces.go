@@ -0,0 +1,80 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netns
+
+import (
+	"sync"
+
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+)
+
+// Namespaces - network namespaces resolved for connections, shared by the Request and Close of a chain element
+// The network namespace of a connection is resolved by its first Request only.  A Close must not depend on resolving
+// it again, since an inode:// url no longer resolves once the last process in the network namespace exited.
+type Namespaces struct {
+	// namespaces - resolved network namespaces, keyed by connection id
+	namespaces map[string]*linuxnamespace.NetNamespace
+	mu         sync.Mutex
+}
+
+// NewNamespaces - returns new Namespaces
+func NewNamespaces() *Namespaces {
+	return &Namespaces{
+		namespaces: make(map[string]*linuxnamespace.NetNamespace),
+	}
+}
+
+// Resolve - returns the network namespace recorded for the connection with id, resolving netNSURL and recording the
+// result if there is none
+func (n *Namespaces) Resolve(id, netNSURL string) (*linuxnamespace.NetNamespace, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if ns, ok := n.namespaces[id]; ok {
+		return ns, nil
+	}
+	ns, err := Resolve(netNSURL)
+	if err != nil {
+		return nil, err
+	}
+	n.namespaces[id] = ns
+	return ns, nil
+}
+
+// Load - returns the network namespace recorded for the connection with id, for its Close
+// If none was recorded, as after a restart, netNSURL is resolved instead, and nil is returned if it does not resolve:
+// vppagent deletes interfaces by name, so a Close does not need the network namespace to succeed.
+func (n *Namespaces) Load(id, netNSURL string) *linuxnamespace.NetNamespace {
+	n.mu.Lock()
+	ns, ok := n.namespaces[id]
+	n.mu.Unlock()
+	if ok {
+		return ns
+	}
+	if ns, err := Resolve(netNSURL); err == nil {
+		return ns
+	}
+	return nil
+}
+
+// Delete - forgets the network namespace of the connection with id
+func (n *Namespaces) Delete(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.namespaces, id)
+}
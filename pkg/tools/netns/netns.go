@@ -0,0 +1,138 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+// Package netns provides a resolver for the network namespace references of the kernel mechanism
+// A kernel.NetNSURL may be given as:
+//             file:///proc/<pid>/ns/net, file:///var/run/netns/<name> - a network namespace file
+//             netns://<name> - a named network namespace, as created by `ip netns add <name>`
+//             pid://<pid> - the network namespace of a process
+//             inode://<inode> - the network namespace with the inode number <inode>
+// Named network namespaces are handed to vppagent by name, since their name outlives any process using them.
+package netns
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+const (
+	// FileScheme - scheme of network namespace file references
+	FileScheme = "file"
+	// NameScheme - scheme of named network namespace references
+	NameScheme = "netns"
+	// PIDScheme - scheme of process id references
+	PIDScheme = "pid"
+	// InodeScheme - scheme of inode number references
+	InodeScheme = "inode"
+	// NamedNetNSDir - directory holding the files of named network namespaces
+	NamedNetNSDir = "/var/run/netns"
+)
+
+// Resolve - returns the vppagent NetNamespace netNSURL refers to
+func Resolve(netNSURL string) (*linuxnamespace.NetNamespace, error) {
+	u, err := url.Parse(netNSURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid network namespace url %q", netNSURL)
+	}
+	switch u.Scheme {
+	case FileScheme:
+		if u.Path == "" {
+			return nil, errors.Errorf("network namespace url %q has no path", netNSURL)
+		}
+		if name, ok := namedNetNS(u.Path); ok {
+			return nsid(name), nil
+		}
+		return fd(u.Path), nil
+	case NameScheme:
+		name := value(u)
+		if name == "" || strings.Contains(name, "/") {
+			return nil, errors.Errorf("network namespace url %q has an invalid name", netNSURL)
+		}
+		return nsid(name), nil
+	case PIDScheme:
+		pid, err := strconv.ParseUint(value(u), 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "network namespace url %q has an invalid pid", netNSURL)
+		}
+		return &linuxnamespace.NetNamespace{
+			Type:      linuxnamespace.NetNamespace_PID,
+			Reference: strconv.FormatUint(pid, 10),
+		}, nil
+	case InodeScheme:
+		filename, err := netnsinode.LinuxNetNSFileName(value(u))
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to resolve network namespace url %q", netNSURL)
+		}
+		return fd(filename), nil
+	default:
+		return nil, errors.Errorf("network namespace url %q has unsupported scheme %q", netNSURL, u.Scheme)
+	}
+}
+
+// Filename - returns the name of a file of the network namespace ns
+func Filename(ns *linuxnamespace.NetNamespace) string {
+	switch ns.GetType() {
+	case linuxnamespace.NetNamespace_NSID:
+		return filepath.Join(NamedNetNSDir, ns.GetReference())
+	case linuxnamespace.NetNamespace_PID:
+		return filepath.Join("/proc", ns.GetReference(), "ns", "net")
+	default:
+		return ns.GetReference()
+	}
+}
+
+// value returns the part of u following the scheme, which is parsed as the host of u for "<scheme>://<value>" and as
+// opaque for "<scheme>:<value>"
+func value(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return u.Host + u.Path
+}
+
+// namedNetNS returns the name of the named network namespace filename is the file of, if it is one
+func namedNetNS(filename string) (string, bool) {
+	filename = filepath.Clean(filename)
+	for _, dir := range []string{NamedNetNSDir, "/run/netns"} {
+		if filepath.Dir(filename) == dir {
+			return filepath.Base(filename), true
+		}
+	}
+	return "", false
+}
+
+func nsid(name string) *linuxnamespace.NetNamespace {
+	return &linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_NSID,
+		Reference: name,
+	}
+}
+
+func fd(filename string) *linuxnamespace.NetNamespace {
+	return &linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_FD,
+		Reference: filename,
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netns_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	linuxnamespace "go.ligato.io/vpp-agent/v3/proto/ligato/linux/namespace"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netns"
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+func TestResolve(t *testing.T) {
+	for netNSURL, expected := range map[string]*linuxnamespace.NetNamespace{
+		"file:///proc/12/ns/net":      {Type: linuxnamespace.NetNamespace_FD, Reference: "/proc/12/ns/net"},
+		"file:///var/run/netns/red":   {Type: linuxnamespace.NetNamespace_NSID, Reference: "red"},
+		"file:///run/netns/red":       {Type: linuxnamespace.NetNamespace_NSID, Reference: "red"},
+		"netns://red":                 {Type: linuxnamespace.NetNamespace_NSID, Reference: "red"},
+		"netns:red":                   {Type: linuxnamespace.NetNamespace_NSID, Reference: "red"},
+		"pid://12":                    {Type: linuxnamespace.NetNamespace_PID, Reference: "12"},
+		"pid:12":                      {Type: linuxnamespace.NetNamespace_PID, Reference: "12"},
+		"file:///var/run/netns/../12": {Type: linuxnamespace.NetNamespace_FD, Reference: "/var/run/netns/../12"},
+	} {
+		ns, err := netns.Resolve(netNSURL)
+		require.NoError(t, err, netNSURL)
+		assert.Equal(t, expected, ns, netNSURL)
+	}
+}
+
+func TestResolve_Invalid(t *testing.T) {
+	for _, netNSURL := range []string{
+		"",
+		"file://",
+		"netns://",
+		"pid://self",
+		"tcp://127.0.0.1",
+		"inode://not-a-number",
+	} {
+		_, err := netns.Resolve(netNSURL)
+		assert.Error(t, err, netNSURL)
+	}
+}
+
+func TestResolve_Inode(t *testing.T) {
+	inode, err := netnsinode.GetMyNetNSInodeNum()
+	require.NoError(t, err)
+	ns, err := netns.Resolve(fmt.Sprintf("inode://%d", inode))
+	require.NoError(t, err)
+	assert.Equal(t, linuxnamespace.NetNamespace_FD, ns.GetType())
+	resolvedInode, err := netnsinode.GetNetNSInodeNum(ns.GetReference())
+	require.NoError(t, err)
+	assert.Equal(t, inode, resolvedInode)
+}
+
+func TestFilename(t *testing.T) {
+	assert.Equal(t, "/proc/12/ns/net", netns.Filename(&linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_FD,
+		Reference: "/proc/12/ns/net",
+	}))
+	assert.Equal(t, "/var/run/netns/red", netns.Filename(&linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_NSID,
+		Reference: "red",
+	}))
+	assert.Equal(t, "/proc/12/ns/net", netns.Filename(&linuxnamespace.NetNamespace{
+		Type:      linuxnamespace.NetNamespace_PID,
+		Reference: "12",
+	}))
+}
+
+func TestNamespaces(t *testing.T) {
+	red := &linuxnamespace.NetNamespace{Type: linuxnamespace.NetNamespace_NSID, Reference: "red"}
+	blue := &linuxnamespace.NetNamespace{Type: linuxnamespace.NetNamespace_NSID, Reference: "blue"}
+	namespaces := netns.NewNamespaces()
+
+	_, err := namespaces.Resolve("1", "tcp://127.0.0.1")
+	assert.Error(t, err)
+	ns, err := namespaces.Resolve("1", "netns://red")
+	require.NoError(t, err)
+	assert.Equal(t, red, ns)
+
+	// The network namespace resolved first is kept, even if the url no longer resolves
+	ns, err = namespaces.Resolve("1", "netns://blue")
+	require.NoError(t, err)
+	assert.Equal(t, red, ns)
+	assert.Equal(t, red, namespaces.Load("1", "tcp://127.0.0.1"))
+
+	// Without a recorded network namespace the url is resolved on a best effort basis
+	namespaces.Delete("1")
+	assert.Nil(t, namespaces.Load("1", "tcp://127.0.0.1"))
+	assert.Equal(t, blue, namespaces.Load("1", "netns://blue"))
+}
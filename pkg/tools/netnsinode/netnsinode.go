@@ -20,12 +20,8 @@
 package netnsinode
 
 import (
-	"io/ioutil"
 	"os"
-	"path"
-	"path/filepath"
 	"strconv"
-	"strings"
 	"syscall"
 	"unicode"
 
@@ -64,50 +60,20 @@ func GetMyNetNSInodeNum() (uint64, error) {
 	return getInode(netnsfile)
 }
 
-// resolvePodNsByInode Traverse /proc/<pid>/<suffix> files,
-// compare their inodes with inode parameter and returns file if inode matches
-func resolveNSByInode(inode uint64) (string, error) {
-	files, err := ioutil.ReadDir("/proc")
-	if err != nil {
-		return "", errors.Wrap(err, "can't read /proc directory")
-	}
-	var filename string
-	for _, f := range files {
-		name := f.Name()
-		if isDigits(name) {
-			fn := path.Join("/proc", name, "/ns/net")
-			tryInode, err := getInode(fn)
-			if err != nil {
-				continue
-			}
-			if tryInode == inode {
-				filename = fn
-				if cmdline, err := getCmdline(name); err == nil && strings.Contains(cmdline, "pause") {
-					return filename, nil
-				}
-			}
-		}
-	}
-	if filename != "" {
-		return filename, nil
-	}
-
-	return "", errors.Errorf("/proc/${pid}/net/ns with inode %d not found", inode)
+// GetNetNSInodeNum - returns the inodeNumber of the Linux Network Namespace file filename, for example
+// /proc/<pid>/ns/net
+func GetNetNSInodeNum(filename string) (uint64, error) {
+	return getInode(filename)
 }
 
+// defaultResolver - Resolver used by LinuxNetNSFileName
+var defaultResolver = NewResolver()
+
 // LinuxNetNSFileName returns a filename of a file from /proc/*/ns/net that has an inode matching inodeString
 func LinuxNetNSFileName(inodeString string) (string, error) {
 	inodeNum, err := strconv.ParseUint(inodeString, 10, 64)
 	if err != nil {
 		return "", errors.Errorf("inodeString must be an unsigned int, instead was: \"%s\"", inodeString)
 	}
-	return resolveNSByInode(inodeNum)
-}
-
-func getCmdline(pid string) (string, error) {
-	data, err := ioutil.ReadFile(path.Join("/proc/", filepath.Clean(pid), "cmdline"))
-	if err != nil {
-		return "", err
-	}
-	return string(data), nil
+	return defaultResolver.Resolve(inodeNum)
 }
@@ -0,0 +1,242 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsinode
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultProcRoot = "/proc"
+)
+
+// Preference - returns true if the network namespace file of the process pid should be preferred over those of other
+// processes in the same network namespace
+//             procRoot - the proc filesystem the process is found in
+//             pid - the process id, as named in procRoot
+type Preference func(procRoot, pid string) bool
+
+// PreferPause - prefers the pause container of a Kubernetes pod, whose process lives as long as the pod, by looking for
+// "pause" in the cmdline of the process
+func PreferPause(procRoot, pid string) bool {
+	data, err := ioutil.ReadFile(filepath.Join(procRoot, filepath.Clean(pid), "cmdline"))
+	return err == nil && strings.Contains(string(data), "pause")
+}
+
+// Resolver - resolves network namespace inode numbers to <procRoot>/<pid>/ns/net files
+// Resolved files are cached and validated on every lookup, so a process exiting only invalidates its own entry.  On a
+// miss the processes started since the last scan of procRoot are inspected first, and only if that does not resolve
+// the inode are the known processes inspected again, catching those that moved to another network namespace.
+type Resolver struct {
+	procRoot string
+	prefer   Preference
+	// processes - network namespace inode and preference of every process seen, keyed by pid
+	processes map[string]*process
+	// resolved - the pid resolved for an inode, keyed by inode
+	resolved map[uint64]string
+	mu       sync.Mutex
+}
+
+type process struct {
+	inode uint64
+	// preferred - nil until the Preference has been evaluated for the process
+	preferred *bool
+}
+
+// ResolverOption - option for NewResolver
+type ResolverOption func(r *Resolver)
+
+// WithProcRoot - sets the root of the proc filesystem to resolve in.  Default /proc
+func WithProcRoot(procRoot string) ResolverOption {
+	return func(r *Resolver) {
+		r.procRoot = procRoot
+	}
+}
+
+// WithPreference - sets the Preference choosing between processes in the same network namespace.  Default PreferPause
+func WithPreference(prefer Preference) ResolverOption {
+	return func(r *Resolver) {
+		r.prefer = prefer
+	}
+}
+
+// NewResolver - returns a new Resolver
+func NewResolver(opts ...ResolverOption) *Resolver {
+	r := &Resolver{
+		procRoot:  defaultProcRoot,
+		prefer:    PreferPause,
+		processes: make(map[string]*process),
+		resolved:  make(map[uint64]string),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve - returns the network namespace file of a process in the network namespace with inode
+func (r *Resolver) Resolve(inode uint64) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if pid, ok := r.resolved[inode]; ok {
+		if r.valid(pid, inode) {
+			return r.filename(pid), nil
+		}
+		delete(r.resolved, inode)
+	}
+	if filename, ok := r.lookup(inode); ok {
+		return filename, nil
+	}
+	for _, full := range []bool{false, true} {
+		if err := r.scan(full); err != nil {
+			return "", err
+		}
+		if filename, ok := r.lookup(inode); ok {
+			return filename, nil
+		}
+	}
+	return "", errors.Errorf("%s/${pid}/ns/net with inode %d not found", r.procRoot, inode)
+}
+
+// Invalidate - drops everything cached about the network namespace with inode, so that the next Resolve of inode
+// starts from a fresh scan of its processes
+func (r *Resolver) Invalidate(inode uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.resolved, inode)
+	for pid, p := range r.processes {
+		if p.inode == inode {
+			delete(r.processes, pid)
+		}
+	}
+}
+
+// lookup resolves inode to a process known from earlier scans, forgetting those no longer in the network namespace.
+// Must be called with r.mu held.
+func (r *Resolver) lookup(inode uint64) (string, bool) {
+	for {
+		pid, ok := r.choose(inode)
+		if !ok {
+			return "", false
+		}
+		if r.valid(pid, inode) {
+			r.resolved[inode] = pid
+			return r.filename(pid), true
+		}
+	}
+}
+
+// valid returns true if the process pid is still in the network namespace with inode, and forgets it otherwise.  A
+// process may have exited, or its pid may have been reused in another network namespace.  Must be called with r.mu
+// held.
+func (r *Resolver) valid(pid string, inode uint64) bool {
+	if tryInode, err := getInode(r.filename(pid)); err == nil && tryInode == inode {
+		return true
+	}
+	delete(r.processes, pid)
+	return false
+}
+
+// scan forgets the processes that have exited and records the inodes of the processes started since the last scan.
+// If full is true, the inodes of the known processes are recorded again as well, and those whose inode changed are
+// forgotten under their old one.  Must be called with r.mu held.
+func (r *Resolver) scan(full bool) error {
+	// Only the names are read, since stat-ing every entry of a large proc filesystem dominates the cost of a scan
+	dir, err := os.Open(r.procRoot)
+	if err != nil {
+		return errors.Wrapf(err, "can't read %s directory", r.procRoot)
+	}
+	names, err := dir.Readdirnames(-1)
+	_ = dir.Close()
+	if err != nil {
+		return errors.Wrapf(err, "can't read %s directory", r.procRoot)
+	}
+	alive := make(map[string]bool, len(names))
+	for _, pid := range names {
+		if !isDigits(pid) {
+			continue
+		}
+		known, ok := r.processes[pid]
+		if ok && !full {
+			alive[pid] = true
+			continue
+		}
+		inode, err := getInode(r.filename(pid))
+		if err != nil {
+			continue
+		}
+		alive[pid] = true
+		if !ok || known.inode != inode {
+			r.processes[pid] = &process{inode: inode}
+		}
+	}
+	for pid := range r.processes {
+		if !alive[pid] {
+			delete(r.processes, pid)
+		}
+	}
+	return nil
+}
+
+// choose returns a known process in the network namespace with inode, preferring those the Preference holds for.
+// Must be called with r.mu held.
+func (r *Resolver) choose(inode uint64) (string, bool) {
+	var candidates []string
+	for pid, p := range r.processes {
+		if p.inode == inode {
+			candidates = append(candidates, pid)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	// Lowest pid first, so that the choice does not depend on map order
+	sortPids(candidates)
+	for _, pid := range candidates {
+		p := r.processes[pid]
+		if p.preferred == nil {
+			preferred := r.prefer(r.procRoot, pid)
+			p.preferred = &preferred
+		}
+		if *p.preferred {
+			return pid, true
+		}
+	}
+	return candidates[0], true
+}
+
+func (r *Resolver) filename(pid string) string {
+	return filepath.Join(r.procRoot, pid, "ns", "net")
+}
+
+func sortPids(pids []string) {
+	sort.Slice(pids, func(i, j int) bool {
+		if len(pids[i]) != len(pids[j]) {
+			return len(pids[i]) < len(pids[j])
+		}
+		return pids[i] < pids[j]
+	})
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build !windows
+
+package netnsinode_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/sdk-vppagent/pkg/tools/netnsinode"
+)
+
+// procTree is a synthetic proc filesystem.  Network namespaces are plain files and the ns/net file of a process is a
+// hard link to the file of its network namespace, so that it has the inode of the network namespace.
+type procTree struct {
+	root string
+}
+
+func newProcTree(tb testing.TB) *procTree {
+	root, err := ioutil.TempDir("", "proc")
+	require.NoError(tb, err)
+	tb.Cleanup(func() { _ = os.RemoveAll(root) })
+	require.NoError(tb, os.Mkdir(filepath.Join(root, "netns"), 0o700))
+	return &procTree{root: root}
+}
+
+// addNetNS creates the network namespace ns and returns its inode
+func (p *procTree) addNetNS(tb testing.TB, ns int) uint64 {
+	filename := filepath.Join(p.root, "netns", strconv.Itoa(ns))
+	require.NoError(tb, ioutil.WriteFile(filename, nil, 0o600))
+	inode, err := netnsinode.GetNetNSInodeNum(filename)
+	require.NoError(tb, err)
+	return inode
+}
+
+// addProcess creates the process pid running cmdline in the network namespace ns
+func (p *procTree) addProcess(tb testing.TB, pid, ns int, cmdline string) {
+	dir := filepath.Join(p.root, strconv.Itoa(pid))
+	require.NoError(tb, os.MkdirAll(filepath.Join(dir, "ns"), 0o700))
+	require.NoError(tb, ioutil.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0o600))
+	require.NoError(tb, os.Link(filepath.Join(p.root, "netns", strconv.Itoa(ns)), filepath.Join(dir, "ns", "net")))
+}
+
+func (p *procTree) removeProcess(tb testing.TB, pid int) {
+	require.NoError(tb, os.RemoveAll(filepath.Join(p.root, strconv.Itoa(pid))))
+}
+
+// moveProcess moves the process pid to the network namespace ns
+func (p *procTree) moveProcess(tb testing.TB, pid, ns int) {
+	require.NoError(tb, os.Remove(p.netNSFile(pid)))
+	require.NoError(tb, os.Link(filepath.Join(p.root, "netns", strconv.Itoa(ns)), p.netNSFile(pid)))
+}
+
+func (p *procTree) netNSFile(pid int) string {
+	return filepath.Join(p.root, strconv.Itoa(pid), "ns", "net")
+}
+
+func TestResolver_PrefersPause(t *testing.T) {
+	tree := newProcTree(t)
+	inode := tree.addNetNS(t, 1)
+	tree.addProcess(t, 10, 1, "nginx")
+	tree.addProcess(t, 11, 1, "/pause")
+	tree.addProcess(t, 12, 1, "sh")
+
+	filename, err := netnsinode.NewResolver(netnsinode.WithProcRoot(tree.root)).Resolve(inode)
+	require.NoError(t, err)
+	assert.Equal(t, tree.netNSFile(11), filename)
+}
+
+func TestResolver_WithPreference(t *testing.T) {
+	tree := newProcTree(t)
+	inode := tree.addNetNS(t, 1)
+	tree.addProcess(t, 10, 1, "nginx")
+	tree.addProcess(t, 11, 1, "/pause")
+	tree.addProcess(t, 9, 1, "init")
+
+	resolver := netnsinode.NewResolver(
+		netnsinode.WithProcRoot(tree.root),
+		netnsinode.WithPreference(func(procRoot, pid string) bool { return false }),
+	)
+	filename, err := resolver.Resolve(inode)
+	require.NoError(t, err)
+	assert.Equal(t, tree.netNSFile(9), filename)
+}
+
+func TestResolver_Invalidation(t *testing.T) {
+	tree := newProcTree(t)
+	inode1 := tree.addNetNS(t, 1)
+	inode2 := tree.addNetNS(t, 2)
+	tree.addProcess(t, 10, 1, "/pause")
+	tree.addProcess(t, 11, 1, "nginx")
+	resolver := netnsinode.NewResolver(netnsinode.WithProcRoot(tree.root))
+
+	filename, err := resolver.Resolve(inode1)
+	require.NoError(t, err)
+	assert.Equal(t, tree.netNSFile(10), filename)
+
+	// Processes started after a scan are found by the next miss
+	tree.addProcess(t, 20, 2, "/pause")
+	filename, err = resolver.Resolve(inode2)
+	require.NoError(t, err)
+	assert.Equal(t, tree.netNSFile(20), filename)
+
+	// A process exiting invalidates its entry
+	tree.removeProcess(t, 10)
+	filename, err = resolver.Resolve(inode1)
+	require.NoError(t, err)
+	assert.Equal(t, tree.netNSFile(11), filename)
+
+	// So does its pid being reused in another network namespace
+	tree.removeProcess(t, 11)
+	tree.addProcess(t, 11, 2, "nginx")
+	_, err = resolver.Resolve(inode1)
+	assert.Error(t, err)
+
+	// Invalidate drops the cached choice, so a newly preferred process is picked up
+	tree.addProcess(t, 21, 2, "/pause")
+	resolver.Invalidate(inode2)
+	tree.removeProcess(t, 20)
+	filename, err = resolver.Resolve(inode2)
+	require.NoError(t, err)
+	assert.Equal(t, tree.netNSFile(21), filename)
+}
+
+func TestResolver_MovedProcess(t *testing.T) {
+	tree := newProcTree(t)
+	inode1 := tree.addNetNS(t, 1)
+	inode2 := tree.addNetNS(t, 2)
+	tree.addProcess(t, 10, 1, "/pause")
+	tree.addProcess(t, 11, 1, "nginx")
+	resolver := netnsinode.NewResolver(netnsinode.WithProcRoot(tree.root))
+
+	filename, err := resolver.Resolve(inode1)
+	require.NoError(t, err)
+	assert.Equal(t, tree.netNSFile(10), filename)
+
+	// A known process moving to a network namespace not seen before is found by a miss
+	tree.moveProcess(t, 11, 2)
+	filename, err = resolver.Resolve(inode2)
+	require.NoError(t, err)
+	assert.Equal(t, tree.netNSFile(11), filename)
+
+	// And is no longer resolved for the network namespace it left
+	tree.removeProcess(t, 10)
+	_, err = resolver.Resolve(inode1)
+	assert.Error(t, err)
+}
+
+const (
+	benchmarkProcesses  = 5000
+	benchmarkNamespaces = 500
+)
+
+// newBenchmarkProcTree returns a proc tree with benchmarkProcesses processes spread over benchmarkNamespaces network
+// namespaces, along with the inodes of the network namespaces
+func newBenchmarkProcTree(b *testing.B) (*procTree, []uint64) {
+	tree := newProcTree(b)
+	inodes := make([]uint64, benchmarkNamespaces)
+	for ns := range inodes {
+		inodes[ns] = tree.addNetNS(b, ns)
+	}
+	for pid := 1; pid <= benchmarkProcesses; pid++ {
+		cmdline := "app"
+		if pid%(benchmarkProcesses/benchmarkNamespaces) == 0 {
+			cmdline = "/pause"
+		}
+		tree.addProcess(b, pid, pid%benchmarkNamespaces, cmdline)
+	}
+	return tree, inodes
+}
+
+// BenchmarkResolver_Scan - the cost of resolving with an empty cache, which scans the whole proc tree like
+// LinuxNetNSFileName did before it was cached
+func BenchmarkResolver_Scan(b *testing.B) {
+	tree, inodes := newBenchmarkProcTree(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := netnsinode.NewResolver(netnsinode.WithProcRoot(tree.root)).Resolve(inodes[i%len(inodes)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkResolver_Cached - the cost of resolving a network namespace resolved before
+func BenchmarkResolver_Cached(b *testing.B) {
+	tree, inodes := newBenchmarkProcTree(b)
+	resolver := netnsinode.NewResolver(netnsinode.WithProcRoot(tree.root))
+	for _, inode := range inodes {
+		if _, err := resolver.Resolve(inode); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := resolver.Resolve(inodes[i%len(inodes)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkResolver_Uncached - the cost of resolving a network namespace after Invalidate, which inspects only the
+// processes of that network namespace again
+func BenchmarkResolver_Uncached(b *testing.B) {
+	tree, inodes := newBenchmarkProcTree(b)
+	resolver := netnsinode.NewResolver(netnsinode.WithProcRoot(tree.root))
+	if _, err := resolver.Resolve(inodes[0]); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		inode := inodes[i%len(inodes)]
+		resolver.Invalidate(inode)
+		if _, err := resolver.Resolve(inode); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
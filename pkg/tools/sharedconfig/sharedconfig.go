@@ -0,0 +1,73 @@
+// Copyright (c) 2020 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharedconfig provides helpers for chain elements managing vpp config shared by several connections, like
+// an acl or a bridge domain
+// The shared config is added to the config of every Request, so that it is created with the first connection.  When
+// a connection is closed, the shared config either goes away with the connection's config if it was the last user,
+// or is updated for the remaining users out of band, see Release.
+package sharedconfig
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/configurator"
+	"go.ligato.io/vpp-agent/v3/proto/ligato/vpp"
+)
+
+// Release - releases the shared config of a connection being closed
+//             vppagentClient - client used to Update the shared config of the remaining connections
+//             vppConfig - vpp config of the connection being closed, deleted once the Close is committed
+//             last - whether the connection was the last user of the shared config
+//             appendConfig - appends the shared config to a vpp config.  When last, it is appended to vppConfig so
+//                            that it is deleted along with the rest of the connection's config.  Otherwise it is
+//                            appended to a fresh config for the remaining connections, which is sent with an Update.
+func Release(ctx context.Context, vppagentClient configurator.ConfiguratorServiceClient, vppConfig *vpp.ConfigData, last bool, appendConfig func(vppConfig *vpp.ConfigData)) error {
+	if last {
+		appendConfig(vppConfig)
+		return nil
+	}
+	update := &vpp.ConfigData{}
+	appendConfig(update)
+	return Update(ctx, vppagentClient, update)
+}
+
+// Update - sends vppConfig to the vppagent out of band, outside of the commit of any connection's config
+func Update(ctx context.Context, vppagentClient configurator.ConfiguratorServiceClient, vppConfig *vpp.ConfigData) error {
+	_, err := vppagentClient.Update(ctx, &configurator.UpdateRequest{
+		Update: &configurator.Config{
+			VppConfig: vppConfig,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error sending config to vppagent %s", vppConfig)
+	}
+	return nil
+}
+
+// Delete - deletes vppConfig from the vppagent out of band, outside of the commit of any connection's config
+func Delete(ctx context.Context, vppagentClient configurator.ConfiguratorServiceClient, vppConfig *vpp.ConfigData) error {
+	_, err := vppagentClient.Delete(ctx, &configurator.DeleteRequest{
+		Delete: &configurator.Config{
+			VppConfig: vppConfig,
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error deleting config from vppagent %s", vppConfig)
+	}
+	return nil
+}